@@ -17,6 +17,15 @@ const (
 	// ReasonMigrationComplete - migration succeeded (used with Ready=True)
 	ReasonMigrationComplete = "MigrationComplete"
 
+	// ReasonDowngradeInProgress - down-migration job is running (targetVersion < currentVersion)
+	ReasonDowngradeInProgress = "DowngradeInProgress"
+
+	// ReasonDowngradeNotAllowed - targetVersion is lower than currentVersion without allowDowngrade
+	ReasonDowngradeNotAllowed = "DowngradeNotAllowed"
+
+	// ReasonPlanComplete - plan-mode job finished, pending migrations are in status.plan
+	ReasonPlanComplete = "PlanComplete"
+
 	// ReasonJobFailed - migration job failed
 	ReasonJobFailed = "JobFailed"
 
@@ -29,15 +38,36 @@ const (
 	// ReasonAWSNotSupported - AWS RDS/Aurora not yet implemented
 	ReasonAWSNotSupported = "AWSNotSupported"
 
+	// ReasonImageResolutionFailed - the migrations image tag could not be resolved to a digest
+	ReasonImageResolutionFailed = "ImageResolutionFailed"
+
+	// ReasonImageVerificationFailed - the migrations image is unsigned or its signature does not match migrations.cosign
+	ReasonImageVerificationFailed = "ImageVerificationFailed"
+
 	// ReasonPreCheckImageVersionFailed - image version precheck failed
 	ReasonPreCheckImageVersionFailed = "PreCheckImageVersionFailed"
 
+	// ReasonPreCheckWorkloadRolloutFailed - a workload is not fully rolled out
+	ReasonPreCheckWorkloadRolloutFailed = "PreCheckWorkloadRolloutFailed"
+
 	// ReasonPreCheckMetricFailed - metric precheck failed
 	ReasonPreCheckMetricFailed = "PreCheckMetricFailed"
 
+	// ReasonPreCheckHTTPFailed - HTTP precheck failed
+	ReasonPreCheckHTTPFailed = "PreCheckHTTPFailed"
+
+	// ReasonPreCheckSQLFailed - SQL precheck failed
+	ReasonPreCheckSQLFailed = "PreCheckSQLFailed"
+
+	// ReasonSQLCheckRunning - waiting for the Job running SQL pre or post checks
+	ReasonSQLCheckRunning = "SQLCheckRunning"
+
 	// ReasonPostCheckFailed - post-migration check failed
 	ReasonPostCheckFailed = "PostCheckFailed"
 
+	// ReasonPostCheckSQLFailed - SQL postcheck failed
+	ReasonPostCheckSQLFailed = "PostCheckSQLFailed"
+
 	// ReasonPostCheckBakeTimeWaiting - waiting for bake time before postcheck
 	ReasonPostCheckBakeTimeWaiting = "PostCheckBakeTimeWaiting"
 )
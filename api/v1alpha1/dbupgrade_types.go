@@ -21,18 +21,188 @@ type DBUpgradeSpec struct {
 	// Runner configuration
 	// +optional
 	Runner *RunnerSpec `json:"runner,omitempty"`
+
+	// Mode selects whether the Job applies migrations or only plans them (defaults to Apply).
+	// Plan runs the engine's dry-run/status command and stores the output in a
+	// ConfigMap so reviewers can see what will run before switching to Apply.
+	// +kubebuilder:validation:Enum=Apply;Plan
+	// +optional
+	Mode MigrationMode `json:"mode,omitempty"`
+
+	// HistoryLimit is the number of runs kept in status.history (defaults to 10).
+	// Changing it does not start a new migration.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
 }
 
+// MigrationMode represents whether the runner applies or plans migrations
+// +kubebuilder:validation:Enum=Apply;Plan
+type MigrationMode string
+
+const (
+	MigrationModeApply MigrationMode = "Apply"
+	MigrationModePlan  MigrationMode = "Plan"
+)
+
 // MigrationsSpec defines the migration configuration
 type MigrationsSpec struct {
-	// Image is the container image to run migrations
-	// +kubebuilder:validation:Required
-	Image string `json:"image"`
+	// Image is the container image holding the migration files.
+	// Exactly one of image and source is set.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Source fetches the migration files from somewhere other than an image
+	// +optional
+	Source *MigrationsSourceSpec `json:"source,omitempty"`
 
 	// Dir is the directory containing migration files
 	// +kubebuilder:default="/migrations"
 	// +optional
 	Dir string `json:"dir,omitempty"`
+
+	// Engine is the migration tool used to apply the files (defaults to atlas).
+	// Not defaulted in the schema so that existing specs keep the same hash.
+	// +kubebuilder:validation:Enum=atlas;golang-migrate;flyway;goose;liquibase
+	// +optional
+	Engine MigrationEngineType `json:"engine,omitempty"`
+
+	// TargetVersion applies migrations only up to this version instead of the
+	// latest one in the image. Passed to the engine as-is: an Atlas/Flyway
+	// version, a numeric golang-migrate/goose version, or a Liquibase tag.
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][A-Za-z0-9._-]*$`
+	// +optional
+	TargetVersion string `json:"targetVersion,omitempty"`
+
+	// AllowDowngrade permits running the engine's down path when targetVersion
+	// is lower than status.currentVersion. Without it such a change is rejected.
+	// Not supported with engine=flyway (Community Edition has no undo).
+	// +optional
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+
+	// ImagePullSecrets are docker config Secrets (kubernetes.io/dockerconfigjson)
+	// used to fetch the migrations image
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// ECR has the operator mint an ECR auth token to fetch the migrations image
+	// from a private ECR repository
+	// +optional
+	ECR *ECRAuthSpec `json:"ecr,omitempty"`
+
+	// Cosign has the operator verify the migrations image's cosign signature
+	// before creating a Job; unsigned images are refused
+	// +optional
+	Cosign *CosignVerificationSpec `json:"cosign,omitempty"`
+
+	// Atlas holds Atlas-specific settings (only valid when engine=atlas)
+	// +optional
+	Atlas *AtlasSpec `json:"atlas,omitempty"`
+
+	// Flyway holds Flyway-specific settings (only valid when engine=flyway)
+	// +optional
+	Flyway *FlywaySpec `json:"flyway,omitempty"`
+
+	// Liquibase holds Liquibase-specific settings (required when engine=liquibase)
+	// +optional
+	Liquibase *LiquibaseSpec `json:"liquibase,omitempty"`
+}
+
+// MigrationEngineType represents the migration tool run by the Job
+// +kubebuilder:validation:Enum=atlas;golang-migrate;flyway;goose;liquibase
+type MigrationEngineType string
+
+const (
+	MigrationEngineAtlas         MigrationEngineType = "atlas"
+	MigrationEngineGolangMigrate MigrationEngineType = "golang-migrate"
+	MigrationEngineFlyway        MigrationEngineType = "flyway"
+	MigrationEngineGoose         MigrationEngineType = "goose"
+	MigrationEngineLiquibase     MigrationEngineType = "liquibase"
+)
+
+// MigrationsSourceSpec selects where the migration files come from when they
+// are not in migrations.image. Exactly one field is set.
+type MigrationsSourceSpec struct {
+	// ConfigMap holds the migration files, one file per key. They are copied
+	// to dir, so a new ConfigMap name is needed to run changed files.
+	// +optional
+	ConfigMap *corev1.LocalObjectReference `json:"configMap,omitempty"`
+
+	// Secret holds the migration files, one file per key, like configMap
+	// +optional
+	Secret *corev1.LocalObjectReference `json:"secret,omitempty"`
+
+	// PersistentVolumeClaim is mounted read-only; the files are under dir
+	// within the volume
+	// +optional
+	PersistentVolumeClaim *PVCSourceSpec `json:"persistentVolumeClaim,omitempty"`
+
+	// OCI is a generic OCI artifact (e.g. pushed with oras push) whose files
+	// are pulled into dir
+	// +optional
+	OCI *OCISourceSpec `json:"oci,omitempty"`
+}
+
+// PVCSourceSpec references an existing PersistentVolumeClaim
+type PVCSourceSpec struct {
+	// ClaimName is the PersistentVolumeClaim in the DBUpgrade's namespace
+	// +kubebuilder:validation:Required
+	ClaimName string `json:"claimName"`
+}
+
+// OCISourceSpec references an OCI artifact
+type OCISourceSpec struct {
+	// Reference is the artifact reference (registry/repository:tag or @digest)
+	// +kubebuilder:validation:Required
+	Reference string `json:"reference"`
+}
+
+// ECRAuthSpec defines how the operator authenticates to a private ECR registry
+type ECRAuthSpec struct {
+	// RoleArn is the IAM role the operator assumes, with ExternalID
+	// "{namespace}/{name}", to call ecr:GetAuthorizationToken. The role also
+	// needs ecr:BatchGetImage and ecr:GetDownloadUrlForLayer on the repository.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^arn:aws:iam::\d{12}:role\/[\w+=,.@-]+$`
+	RoleArn string `json:"roleArn"`
+}
+
+// CosignVerificationSpec defines the key the migrations image must be signed with
+type CosignVerificationSpec struct {
+	// PublicKeySecretRef references the PEM public key (cosign.pub) that
+	// signed the image. Keyless signatures are not supported.
+	// +kubebuilder:validation:Required
+	PublicKeySecretRef corev1.SecretKeySelector `json:"publicKeySecretRef"`
+}
+
+// AtlasSpec defines Atlas-specific migration settings
+type AtlasSpec struct {
+	// RevisionsSchema is the schema Atlas stores its revision table in
+	// +optional
+	RevisionsSchema string `json:"revisionsSchema,omitempty"`
+
+	// DevURLSecretRef references a dev database URL. `atlas migrate down`
+	// needs a scratch database to compute the revert plan, so this is
+	// required when allowDowngrade is set.
+	// +optional
+	DevURLSecretRef *corev1.SecretKeySelector `json:"devURLSecretRef,omitempty"`
+}
+
+// FlywaySpec defines Flyway-specific migration settings
+type FlywaySpec struct {
+	// BaselineOnMigrate baselines a non-empty schema without a history table
+	// +optional
+	BaselineOnMigrate bool `json:"baselineOnMigrate,omitempty"`
+}
+
+// LiquibaseSpec defines Liquibase-specific migration settings
+type LiquibaseSpec struct {
+	// ChangeLogFile is the changelog path relative to the migrations dir
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ChangeLogFile string `json:"changeLogFile"`
 }
 
 // DatabaseSpec defines the database configuration
@@ -42,6 +212,13 @@ type DatabaseSpec struct {
 	// +kubebuilder:validation:Enum=selfHosted;awsRds;awsAurora
 	Type DatabaseType `json:"type"`
 
+	// Engine is the database engine (defaults to postgres). It selects the
+	// connection URL format and default port for IAM auth; for connection
+	// secrets the URL scheme takes precedence.
+	// +kubebuilder:validation:Enum=postgres;mysql;mariadb
+	// +optional
+	Engine DatabaseEngine `json:"engine,omitempty"`
+
 	// Connection configuration
 	// +optional
 	Connection *ConnectionSpec `json:"connection,omitempty"`
@@ -49,6 +226,16 @@ type DatabaseSpec struct {
 	// AWS-specific configuration (placeholders for future use)
 	// +optional
 	AWS *AWSSpec `json:"aws,omitempty"`
+
+	// Vault issues short-lived credentials from HashiCorp Vault's database
+	// secrets engine (selfHosted only, instead of connection)
+	// +optional
+	Vault *VaultSpec `json:"vault,omitempty"`
+
+	// TLS verifies the database server certificate and optionally presents a
+	// client certificate. The files are mounted into the migrate container.
+	// +optional
+	TLS *DatabaseTLSSpec `json:"tls,omitempty"`
 }
 
 // DatabaseType represents the type of database
@@ -61,11 +248,55 @@ const (
 	DatabaseTypeAWSAurora  DatabaseType = "awsAurora"
 )
 
-// ConnectionSpec defines database connection details
+// DatabaseEngine represents the database server engine
+// +kubebuilder:validation:Enum=postgres;mysql;mariadb
+type DatabaseEngine string
+
+const (
+	DatabaseEnginePostgres DatabaseEngine = "postgres"
+	DatabaseEngineMySQL    DatabaseEngine = "mysql"
+	DatabaseEngineMariaDB  DatabaseEngine = "mariadb"
+)
+
+// ConnectionSpec defines database connection details.
+// Either urlSecretRef or the structured fields (host, database, username,
+// passwordSecretRef) are set; the operator builds the URL from the latter.
 type ConnectionSpec struct {
 	// URLSecretRef references a secret containing the database URL
 	// +optional
 	URLSecretRef *corev1.SecretKeySelector `json:"urlSecretRef,omitempty"`
+
+	// Host is the database hostname
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port is the database port (defaults to the engine's default port)
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// Database is the database name
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// Username is the database user
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// PasswordSecretRef references a secret containing the database password
+	// +optional
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// SSLMode uses PostgreSQL sslmode names; for MySQL and MariaDB it is
+	// translated to the tls parameter
+	// +optional
+	// +kubebuilder:validation:Enum=disable;allow;prefer;require;verify-ca;verify-full
+	SSLMode string `json:"sslMode,omitempty"`
+
+	// Params are extra query parameters appended to the connection URL
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
 }
 
 // AWSSpec defines AWS-specific database configuration
@@ -74,7 +305,8 @@ type AWSSpec struct {
 	// RoleArn is the IAM role that the operator will assume to generate RDS auth tokens
 	// This role must:
 	// - Have trust policy allowing the operator's IAM role (via AssumeRole)
-	// - Have rds-db:connect permission for the database
+	// - Have rds-db:connect permission for the database, or
+	//   secretsmanager:GetSecretValue on SecretsManagerSecretArn
 	// The operator has EKS Pod Identity and can assume this role
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Pattern=`^arn:aws:iam::\d{12}:role\/[\w+=,.@-]+$`
@@ -88,8 +320,9 @@ type AWSSpec struct {
 	// +kubebuilder:validation:Required
 	Host string `json:"host"`
 
-	// Port is the database port
-	// +kubebuilder:default=5432
+	// Port is the database port (defaults to 5432 for postgres, 3306 for mysql/mariadb)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
 	Port int32 `json:"port,omitempty"`
 
@@ -100,6 +333,107 @@ type AWSSpec struct {
 	// Username for database access (must be an RDS IAM user)
 	// +kubebuilder:validation:Required
 	Username string `json:"username"`
+
+	// SecretsManagerSecretArn reads the database password from an AWS Secrets
+	// Manager secret (e.g. an RDS-managed master user secret) instead of
+	// generating an RDS IAM auth token. The secret must be a JSON object with
+	// "password" and optionally "username", which takes precedence over Username.
+	// +kubebuilder:validation:Pattern=`^arn:aws:secretsmanager:[a-z0-9-]+:\d{12}:secret:.+$`
+	// +optional
+	SecretsManagerSecretArn string `json:"secretsManagerSecretArn,omitempty"`
+}
+
+// DatabaseTLSMode selects how the database server certificate is verified
+// +kubebuilder:validation:Enum=verify-ca;verify-full
+type DatabaseTLSMode string
+
+const (
+	// DatabaseTLSVerifyCA verifies the certificate chain only
+	DatabaseTLSVerifyCA DatabaseTLSMode = "verify-ca"
+	// DatabaseTLSVerifyFull verifies the chain and that the certificate matches the host
+	DatabaseTLSVerifyFull DatabaseTLSMode = "verify-full"
+)
+
+// DatabaseTLSSpec defines the CA bundle and client certificate for the
+// migration's database connection. Exactly one CA source must be set.
+type DatabaseTLSSpec struct {
+	// Mode is the verification mode (defaults to verify-full).
+	// MySQL and MariaDB only support verify-full.
+	// +optional
+	// +kubebuilder:default=verify-full
+	Mode DatabaseTLSMode `json:"mode,omitempty"`
+
+	// CAConfigMapRef references a ConfigMap key holding the PEM CA bundle
+	// +optional
+	CAConfigMapRef *corev1.ConfigMapKeySelector `json:"caConfigMapRef,omitempty"`
+
+	// CASecretRef references a Secret key holding the PEM CA bundle
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// UseRDSCA uses the AWS RDS CA bundle shipped with the operator
+	// (awsRds and awsAurora only)
+	// +optional
+	UseRDSCA bool `json:"useRDSCA,omitempty"`
+
+	// ClientCertSecretRef references a kubernetes.io/tls Secret (tls.crt and
+	// tls.key) presented as the client certificate. Not supported with the
+	// flyway and liquibase engines.
+	// +optional
+	ClientCertSecretRef *corev1.LocalObjectReference `json:"clientCertSecretRef,omitempty"`
+}
+
+// VaultSpec defines dynamic credentials from HashiCorp Vault.
+// The operator logs in with Kubernetes auth using a token for ServiceAccountName
+// in the DBUpgrade's namespace, requests a lease for Role, and revokes the
+// lease when the migration Job finishes.
+type VaultSpec struct {
+	// Address is the Vault server URL (e.g. https://vault.vault.svc:8200). It
+	// must be https and on a host the operator allows (VAULT_ALLOWED_HOSTS).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Address string `json:"address"`
+
+	// AuthMount is the Kubernetes auth method mount path (defaults to kubernetes)
+	// +optional
+	AuthMount string `json:"authMount,omitempty"`
+
+	// AuthRole is the Kubernetes auth role to log in with. Bind it to the
+	// DBUpgrade's namespace (bound_service_account_namespaces).
+	// +kubebuilder:validation:Required
+	AuthRole string `json:"authRole"`
+
+	// ServiceAccountName is the ServiceAccount whose token is used to log in
+	// (defaults to default)
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Mount is the database secrets engine mount path (defaults to database)
+	// +optional
+	Mount string `json:"mount,omitempty"`
+
+	// Role is the database secrets engine role to request credentials for.
+	// Its TTL must cover the time the Job waits to be scheduled plus its runtime.
+	// +kubebuilder:validation:Required
+	Role string `json:"role"`
+
+	// Host is the database host
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+
+	// Port is the database port (defaults to 5432 for postgres, 3306 for mysql/mariadb)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// DBName is the database name
+	// +kubebuilder:validation:Required
+	DBName string `json:"dbName"`
+
+	// Params are added to the connection URL query (e.g. sslmode: disable)
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
 }
 
 // ChecksSpec defines pre and post upgrade checks
@@ -119,11 +453,27 @@ type PreChecksSpec struct {
 	// +optional
 	MinPodVersions []MinPodVersionCheck `json:"minPodVersions,omitempty"`
 
+	// Workloads that must be fully rolled out
+	// +optional
+	WorkloadRollout []WorkloadRolloutCheck `json:"workloadRollout,omitempty"`
+
 	// Metrics to check (list-as-map keyed by name for GitOps-friendly edits)
 	// +listType=map
 	// +listMapKey=name
 	// +optional
 	Metrics []MetricCheck `json:"metrics,omitempty"`
+
+	// HTTP endpoints to check
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	HTTP []HTTPCheck `json:"http,omitempty"`
+
+	// SQL queries to check against the target database
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	SQL []SQLCheck `json:"sql,omitempty"`
 }
 
 // PostChecksSpec defines post-upgrade checks
@@ -133,6 +483,18 @@ type PostChecksSpec struct {
 	// +listMapKey=name
 	// +optional
 	Metrics []MetricCheck `json:"metrics,omitempty"`
+
+	// HTTP endpoints to check
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	HTTP []HTTPCheck `json:"http,omitempty"`
+
+	// SQL queries to check against the target database
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	SQL []SQLCheck `json:"sql,omitempty"`
 }
 
 // MinPodVersionCheck defines a minimum pod version check
@@ -141,7 +503,7 @@ type MinPodVersionCheck struct {
 	// +kubebuilder:validation:Required
 	Selector metav1.LabelSelector `json:"selector"`
 
-	// MinVersion is the minimum required version (ImageTag-only semver)
+	// MinVersion is the minimum required version (semver)
 	// +kubebuilder:validation:Required
 	MinVersion string `json:"minVersion"`
 
@@ -149,7 +511,18 @@ type MinPodVersionCheck struct {
 	// +optional
 	ContainerName string `json:"containerName,omitempty"`
 
-	// StrictMode controls behavior when pods have non-semver image tags.
+	// VersionSource is where a pod's version is read from:
+	// imageTag (default) parses the container image tag;
+	// label:<key> or annotation:<key> reads a pod label or annotation;
+	// env:<VAR> reads a literal environment variable of the container;
+	// imageLabel reads the org.opencontainers.image.version label of the
+	// image from its registry, so digest-pinned images can be checked.
+	// +kubebuilder:validation:Pattern=`^(imageTag|imageLabel|(label|annotation|env):.+)$`
+	// +kubebuilder:default=imageTag
+	// +optional
+	VersionSource string `json:"versionSource,omitempty"`
+
+	// StrictMode controls behavior when pods have non-semver versions.
 	// When true (default): non-semver pods cause check failure.
 	// When false: non-semver pods are skipped (not counted as pass or fail).
 	// +kubebuilder:default=true
@@ -162,6 +535,53 @@ type MinPodVersionCheck struct {
 	DisallowDowngrade bool `json:"disallowDowngrade,omitempty"`
 }
 
+// Version sources of a MinPodVersionCheck. Label, annotation and env sources
+// are the prefix followed by the key or variable name.
+const (
+	VersionSourceImageTag         = "imageTag"
+	VersionSourceImageLabel       = "imageLabel"
+	VersionSourceLabelPrefix      = "label:"
+	VersionSourceAnnotationPrefix = "annotation:"
+	VersionSourceEnvPrefix        = "env:"
+)
+
+// WorkloadRolloutCheck requires Deployments, StatefulSets and DaemonSets to be
+// fully rolled out: the spec generation observed, all replicas updated and
+// available, and no pods left from previous revisions. Exactly one of
+// workloads and selector is set.
+type WorkloadRolloutCheck struct {
+	// Workloads to check by kind and name
+	// +optional
+	Workloads []WorkloadReference `json:"workloads,omitempty"`
+
+	// Selector matches Deployments, StatefulSets and DaemonSets by label
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// WorkloadReference names a workload in the DBUpgrade's namespace
+type WorkloadReference struct {
+	// Kind of the workload
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	Kind WorkloadKind `json:"kind"`
+
+	// Name of the workload
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// WorkloadKind is the kind of a rollout-checked workload
+// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+type WorkloadKind string
+
+const (
+	WorkloadKindDeployment  WorkloadKind = "Deployment"
+	WorkloadKindStatefulSet WorkloadKind = "StatefulSet"
+	WorkloadKindDaemonSet   WorkloadKind = "DaemonSet"
+)
+
 // MetricCheck defines a metric check
 type MetricCheck struct {
 	// Name is required and must be unique (list-as-map semantics).
@@ -170,18 +590,22 @@ type MetricCheck struct {
 	Name string `json:"name"`
 
 	// Source of the metric
-	// +kubebuilder:validation:Enum=Custom;External
+	// +kubebuilder:validation:Enum=Custom;External;Prometheus
 	// +kubebuilder:default=Custom
 	// +optional
 	Source MetricSource `json:"source,omitempty"`
 
-	// MetricName is the name of the metric
-	// +kubebuilder:validation:Required
-	MetricName string `json:"metricName"`
+	// MetricName is the name of the metric (required for Custom and External)
+	// +optional
+	MetricName string `json:"metricName,omitempty"`
 
-	// Target defines what to query for the metric
-	// +kubebuilder:validation:Required
-	Target MetricTarget `json:"target"`
+	// Target defines what to query for the metric (required for Custom and External)
+	// +optional
+	Target *MetricTarget `json:"target,omitempty"`
+
+	// Prometheus defines the PromQL query (required for source=Prometheus)
+	// +optional
+	Prometheus *PrometheusQuery `json:"prometheus,omitempty"`
 
 	// Threshold defines the threshold condition
 	// +kubebuilder:validation:Required
@@ -203,14 +627,56 @@ type MetricCheck struct {
 }
 
 // MetricSource represents the source of a metric
-// +kubebuilder:validation:Enum=Custom;External
+// +kubebuilder:validation:Enum=Custom;External;Prometheus
 type MetricSource string
 
 const (
-	MetricSourceCustom   MetricSource = "Custom"
-	MetricSourceExternal MetricSource = "External"
+	MetricSourceCustom     MetricSource = "Custom"
+	MetricSourceExternal   MetricSource = "External"
+	MetricSourcePrometheus MetricSource = "Prometheus"
 )
 
+// PrometheusQuery queries a Prometheus-compatible HTTP API directly, without
+// prometheus-adapter
+type PrometheusQuery struct {
+	// URL of the server, without the /api/v1 path
+	// (e.g. http://prometheus-operated.monitoring:9090). Its host must be
+	// allowed by the operator (PROMETHEUS_ALLOWED_HOSTS).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Query is an instant PromQL query. Each series of a vector result is one
+	// value for reduce; a scalar result is a single value.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query"`
+
+	// BearerTokenSecretRef references a Secret key holding a bearer token
+	// +optional
+	BearerTokenSecretRef *corev1.SecretKeySelector `json:"bearerTokenSecretRef,omitempty"`
+
+	// TLS configures server verification and a client certificate
+	// +optional
+	TLS *PrometheusTLSSpec `json:"tls,omitempty"`
+}
+
+// PrometheusTLSSpec configures TLS to a Prometheus server
+type PrometheusTLSSpec struct {
+	// CASecretRef references a Secret key holding the PEM CA bundle
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// ClientCertSecretRef references a kubernetes.io/tls Secret (tls.crt and
+	// tls.key) presented as the client certificate
+	// +optional
+	ClientCertSecretRef *corev1.LocalObjectReference `json:"clientCertSecretRef,omitempty"`
+
+	// InsecureSkipVerify disables server certificate verification
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
 // MetricTarget defines what to query for a metric
 // NOTE: Cross-field validation (e.g., type=Pods requires Pods to be set) must be enforced
 // in controller validation logic in Phase 1, as CRD schema alone cannot easily enforce this.
@@ -283,6 +749,142 @@ type ExternalTarget struct {
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
+// SQLCheck runs a read-only query against the target database, with the
+// migration's connection, and compares its scalar result to a threshold
+type SQLCheck struct {
+	// Name is required and must be unique (list-as-map semantics).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Query is a single statement returning one row with one numeric (or
+	// boolean) column, e.g. SELECT count(*) FROM legacy_orders
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query"`
+
+	// Threshold defines the threshold condition
+	// +kubebuilder:validation:Required
+	Threshold ThresholdSpec `json:"threshold"`
+
+	// TimeoutSeconds is the statement timeout
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=300
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// BakeSeconds is the time to wait after the migration before evaluating
+	// (postchecks only)
+	// +kubebuilder:default=0
+	// +optional
+	BakeSeconds int32 `json:"bakeSeconds,omitempty"`
+}
+
+// HTTPCheck calls an HTTP endpoint and passes on the expected status code and,
+// if jsonPath is set, a value from the JSON response compared with threshold.
+// Exactly one of url and service is set.
+type HTTPCheck struct {
+	// Name is required and must be unique (list-as-map semantics).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// URL is an absolute http(s) URL. Its host must be allowed by the
+	// operator (HTTP_CHECK_ALLOWED_HOSTS).
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Service targets a Service in the DBUpgrade's namespace
+	// +optional
+	Service *HTTPServiceRef `json:"service,omitempty"`
+
+	// Method is the request method
+	// +kubebuilder:validation:Enum=GET;HEAD;POST
+	// +kubebuilder:default=GET
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Headers to send with the request
+	// +optional
+	Headers []HTTPHeader `json:"headers,omitempty"`
+
+	// Body is sent with POST requests
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// ExpectedStatusCodes the response must have (default: any 2xx)
+	// +optional
+	ExpectedStatusCodes []int32 `json:"expectedStatusCodes,omitempty"`
+
+	// JSONPath selects a single value from the JSON response body, in kubectl
+	// syntax (e.g. {.migration.pending}). Numbers, numeric strings and
+	// booleans (true=1, false=0) are compared with threshold.
+	// +kubebuilder:validation:Pattern=`^\{.+\}$`
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+
+	// Threshold for the JSONPath value (required with jsonPath)
+	// +optional
+	Threshold *ThresholdSpec `json:"threshold,omitempty"`
+
+	// TimeoutSeconds is the request timeout
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// BakeSeconds is the time to wait after the migration before evaluating
+	// (postchecks only)
+	// +kubebuilder:default=0
+	// +optional
+	BakeSeconds int32 `json:"bakeSeconds,omitempty"`
+}
+
+// HTTPServiceRef targets a port of a Service in the DBUpgrade's namespace
+type HTTPServiceRef struct {
+	// Name of the Service
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Port of the Service
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Path of the request, including any query string
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Scheme of the request
+	// +kubebuilder:validation:Enum=http;https
+	// +kubebuilder:default=http
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// HTTPHeader is a request header. Exactly one of value and valueSecretRef is set.
+type HTTPHeader struct {
+	// Name of the header
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Value of the header
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// ValueSecretRef references a Secret key holding the value
+	// +optional
+	ValueSecretRef *corev1.SecretKeySelector `json:"valueSecretRef,omitempty"`
+}
+
 // ThresholdSpec defines a threshold condition
 type ThresholdSpec struct {
 	// Operator for comparison
@@ -327,8 +929,77 @@ type RunnerSpec struct {
 	// establishing connections, not for keeping them open
 	// +optional
 	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// ConnectionMode selects how the runner receives the database connection:
+	// Env (default) or File. File is supported for atlas, flyway and liquibase.
+	// +optional
+	ConnectionMode ConnectionMode `json:"connectionMode,omitempty"`
+
+	// Resources for the fetch and migrate containers
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector for the runner pod
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations for the runner pod
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity for the runner pod
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName for the runner pod
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// ServiceAccountName the runner pod runs as (defaults to the namespace's default)
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// ImagePullSecrets for the runner and migrations images
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// PodLabels are added to the runner pod
+	// +optional
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// PodAnnotations are added to the runner pod
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// PodSecurityContext for the runner pod
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// SecurityContext for the fetch and migrate containers
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
 }
 
+// ConnectionMode selects how the runner receives the database connection
+// +kubebuilder:validation:Enum=Env;File
+type ConnectionMode string
+
+const (
+	// ConnectionModeEnv passes the connection through env vars sourced from
+	// the operator-managed Secret
+	ConnectionModeEnv ConnectionMode = "Env"
+	// ConnectionModeFile mounts the operator-managed Secret read-only and has
+	// the runner read the connection from a config file, so credentials never
+	// appear in the process environment
+	ConnectionModeFile ConnectionMode = "File"
+)
+
 // DBUpgradeStatus defines the observed state of DBUpgrade
 type DBUpgradeStatus struct {
 	// ObservedGeneration reflects the generation of the most recently observed DBUpgrade
@@ -340,6 +1011,39 @@ type DBUpgradeStatus struct {
 	// +optional
 	JobCompletedAt *metav1.Time `json:"jobCompletedAt,omitempty"`
 
+	// CurrentVersion is the migration version the database was last moved to,
+	// as reported by the engine. Compared with spec.migrations.targetVersion
+	// to detect downgrades.
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+
+	// AppliedMigrations summarizes what the last successful Job applied
+	// +optional
+	AppliedMigrations *AppliedMigrationsStatus `json:"appliedMigrations,omitempty"`
+
+	// LastAppliedImage is the migrations image of the last successful Job
+	// +optional
+	LastAppliedImage string `json:"lastAppliedImage,omitempty"`
+
+	// ResolvedImage is the migrations image of the current Job, pinned to
+	// the digest its tag pointed to when the Job was created
+	// +optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+
+	// LastFailure describes the most recent failed migration Job
+	// +optional
+	LastFailure *FailureStatus `json:"lastFailure,omitempty"`
+
+	// History lists the most recent migration Jobs, oldest first, bounded by
+	// spec.historyLimit. Entries outlive the Jobs themselves, which are
+	// deleted when the spec changes.
+	// +optional
+	History []MigrationHistoryEntry `json:"history,omitempty"`
+
+	// Plan holds the result of the most recent Plan-mode run
+	// +optional
+	Plan *PlanStatus `json:"plan,omitempty"`
+
 	// Conditions represent the latest available observations of DBUpgrade's state
 	// +listType=map
 	// +listMapKey=type
@@ -349,6 +1053,124 @@ type DBUpgradeStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// PlanStatus summarizes a Plan-mode run. The full engine output is stored in
+// the referenced ConfigMap (key "plan").
+type PlanStatus struct {
+	// JobName is the Job that produced the plan
+	JobName string `json:"jobName"`
+
+	// Image is the migrations image that was planned
+	Image string `json:"image"`
+
+	// ConfigMapName is the operator-owned ConfigMap holding the full output
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// PendingMigrations lists the migrations that Apply would run
+	// +optional
+	PendingMigrations []string `json:"pendingMigrations,omitempty"`
+
+	// GeneratedAt is when the plan output was collected
+	// +optional
+	GeneratedAt *metav1.Time `json:"generatedAt,omitempty"`
+}
+
+// AppliedMigrationsStatus summarizes the migrations applied by a successful Job,
+// parsed from the runner output
+type AppliedMigrationsStatus struct {
+	// JobName is the Job whose output was parsed
+	JobName string `json:"jobName"`
+
+	// Count is the number of migrations the Job applied (0 if already up to date)
+	Count int32 `json:"count"`
+
+	// LastFile is the last migration applied, as named by the engine
+	// (file name, or changeset id for Liquibase)
+	// +optional
+	LastFile string `json:"lastFile,omitempty"`
+}
+
+// FailureStatus describes a failed migration Job
+type FailureStatus struct {
+	// JobName is the failed Job
+	JobName string `json:"jobName"`
+
+	// Container is the container that failed (fetch-migrations or migrate)
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// Type classifies the failure
+	Type FailureType `json:"type"`
+
+	// Excerpt is the tail of the failed container's log, truncated and with
+	// URL passwords redacted
+	// +optional
+	Excerpt string `json:"excerpt,omitempty"`
+
+	// FailedAt is when the Job failed
+	// +optional
+	FailedAt *metav1.Time `json:"failedAt,omitempty"`
+}
+
+// FailureType classifies why a migration Job failed
+// +kubebuilder:validation:Enum=ImagePull;RegistryAuth;EmptyMigrations;SQLError;LockTimeout;DeadlineExceeded;Unknown
+type FailureType string
+
+const (
+	// FailureTypeImagePull - an image could not be pulled or the migrations image was not found
+	FailureTypeImagePull FailureType = "ImagePull"
+	// FailureTypeRegistryAuth - the fetcher was denied access to the migrations image
+	FailureTypeRegistryAuth FailureType = "RegistryAuth"
+	// FailureTypeEmptyMigrations - migrations.dir is missing from the source or has no files
+	FailureTypeEmptyMigrations FailureType = "EmptyMigrations"
+	// FailureTypeSQLError - the database rejected a migration statement
+	FailureTypeSQLError FailureType = "SQLError"
+	// FailureTypeLockTimeout - the engine could not acquire its migration lock
+	FailureTypeLockTimeout FailureType = "LockTimeout"
+	// FailureTypeDeadlineExceeded - the Job ran past runner.activeDeadlineSeconds
+	FailureTypeDeadlineExceeded FailureType = "DeadlineExceeded"
+	// FailureTypeUnknown - none of the above matched
+	FailureTypeUnknown FailureType = "Unknown"
+)
+
+// MigrationHistoryEntry records one migration Job
+type MigrationHistoryEntry struct {
+	// JobName is the migration Job
+	JobName string `json:"jobName"`
+
+	// SpecHash is the spec hash the Job was created for
+	SpecHash string `json:"specHash"`
+
+	// Image is the migrations image the Job ran
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// StartTime is when the Job started (or was created, if not yet started)
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the Job succeeded or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Outcome is the Job result
+	Outcome MigrationOutcome `json:"outcome"`
+
+	// Reason is the Progressing reason last observed for this Job
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// MigrationOutcome is the result of a migration Job
+// +kubebuilder:validation:Enum=Running;Succeeded;Failed
+type MigrationOutcome string
+
+const (
+	MigrationOutcomeRunning   MigrationOutcome = "Running"
+	MigrationOutcomeSucceeded MigrationOutcome = "Succeeded"
+	MigrationOutcomeFailed    MigrationOutcome = "Failed"
+)
+
 // DBUpgradeConditionType represents a condition type
 type DBUpgradeConditionType string
 
@@ -364,6 +1186,8 @@ const (
 	// - PreCheckImageVersionFailed: Image version precheck failed
 	// - PreCheckMetricFailed: Metric precheck failed
 	// - PostCheckFailed: Post-migration check failed
+	// - PostCheckSQLFailed: SQL postcheck failed
+	// - PlanComplete: Plan-mode Job finished; see status.plan
 	// - SecretNotFound: Database connection secret not found
 	// - AWSNotSupported: AWS RDS/Aurora not yet implemented
 	ConditionProgressing DBUpgradeConditionType = "Progressing"
@@ -375,6 +1199,9 @@ const (
 //+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Migration completed successfully"
 //+kubebuilder:printcolumn:name="Progressing",type=string,JSONPath=".status.conditions[?(@.type==\"Progressing\")].status",description="Migration in progress"
 //+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=".status.conditions[?(@.type==\"Progressing\")].reason",description="Current state reason"
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=".status.currentVersion",description="Applied schema version"
+//+kubebuilder:printcolumn:name="Applied",type=integer,JSONPath=".status.appliedMigrations.count",description="Migrations applied by the last Job"
+//+kubebuilder:printcolumn:name="Image",type=string,JSONPath=".status.lastAppliedImage",description="Last applied migrations image",priority=1
 //+kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
 
 // DBUpgrade is the Schema for the dbupgrades API
@@ -18,17 +18,26 @@ package v1alpha1
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/jsonpath"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// AllowInsecureVault makes the webhook accept http:// Vault addresses (dev
+// servers only). main sets it from the operator's ALLOW_INSECURE_VAULT.
+var AllowInsecureVault bool
+
 func (r *DBUpgrade) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
@@ -71,6 +80,11 @@ func (r *DBUpgrade) ValidateDelete() (admission.Warnings, error) {
 func (r *DBUpgrade) validateDBUpgrade() error {
 	var allErrs []error
 
+	// Validate migration engine configuration
+	if err := r.validateMigrations(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
 	// Validate database configuration
 	if err := r.validateDatabase(); err != nil {
 		allErrs = append(allErrs, err)
@@ -87,6 +101,21 @@ func (r *DBUpgrade) validateDBUpgrade() error {
 		if err := r.validateMetrics(); err != nil {
 			allErrs = append(allErrs, err)
 		}
+
+		// Validate workload rollout checks
+		if err := r.validateWorkloadRollout(); err != nil {
+			allErrs = append(allErrs, err)
+		}
+
+		// Validate HTTP checks
+		if err := r.validateHTTPChecks(); err != nil {
+			allErrs = append(allErrs, err)
+		}
+
+		// Validate SQL checks
+		if err := r.validateSQLChecks(); err != nil {
+			allErrs = append(allErrs, err)
+		}
 	}
 
 	if len(allErrs) > 0 {
@@ -104,6 +133,174 @@ func (r *DBUpgrade) validateMinPodVersions() error {
 		if err != nil {
 			return fmt.Errorf("checks.pre.minPodVersions[%d].minVersion %q is not valid semver: %w", i, check.MinVersion, err)
 		}
+		if err := validateVersionSource(check.VersionSource); err != nil {
+			return fmt.Errorf("checks.pre.minPodVersions[%d].versionSource: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateVersionSource checks the key or variable name of label, annotation
+// and env sources
+func validateVersionSource(source string) error {
+	var errs []string
+	switch {
+	case source == "", source == VersionSourceImageTag, source == VersionSourceImageLabel:
+		return nil
+	case strings.HasPrefix(source, VersionSourceLabelPrefix):
+		errs = validation.IsQualifiedName(strings.TrimPrefix(source, VersionSourceLabelPrefix))
+	case strings.HasPrefix(source, VersionSourceAnnotationPrefix):
+		errs = validation.IsQualifiedName(strings.TrimPrefix(source, VersionSourceAnnotationPrefix))
+	case strings.HasPrefix(source, VersionSourceEnvPrefix):
+		errs = validation.IsEnvVarName(strings.TrimPrefix(source, VersionSourceEnvPrefix))
+	default:
+		return fmt.Errorf("%q must be imageTag, imageLabel, label:<key>, annotation:<key> or env:<VAR>", source)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%q: %s", source, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateWorkloadRollout ensures each check names workloads or a selector
+func (r *DBUpgrade) validateWorkloadRollout() error {
+	for i, check := range r.Spec.Checks.Pre.WorkloadRollout {
+		if (len(check.Workloads) == 0) == (check.Selector == nil) {
+			return fmt.Errorf("checks.pre.workloadRollout[%d]: exactly one of workloads and selector must be set", i)
+		}
+		if check.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(check.Selector); err != nil {
+				return fmt.Errorf("checks.pre.workloadRollout[%d].selector: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMigrations ensures engine-specific settings match the selected engine
+func (r *DBUpgrade) validateMigrations() error {
+	m := r.Spec.Migrations
+	engine := m.Engine
+	if engine == "" {
+		engine = MigrationEngineAtlas
+	}
+
+	if m.Atlas != nil && engine != MigrationEngineAtlas {
+		return fmt.Errorf("migrations.atlas is only valid with engine=atlas (got %s)", engine)
+	}
+	if m.Flyway != nil && engine != MigrationEngineFlyway {
+		return fmt.Errorf("migrations.flyway is only valid with engine=flyway (got %s)", engine)
+	}
+	if m.Liquibase != nil && engine != MigrationEngineLiquibase {
+		return fmt.Errorf("migrations.liquibase is only valid with engine=liquibase (got %s)", engine)
+	}
+
+	// golang-migrate has no dry-run or status listing of pending files
+	if r.Spec.Mode == MigrationModePlan && engine == MigrationEngineGolangMigrate {
+		return fmt.Errorf("mode=Plan is not supported with engine=golang-migrate")
+	}
+
+	// golang-migrate and goose versions are integers
+	if m.TargetVersion != "" && (engine == MigrationEngineGolangMigrate || engine == MigrationEngineGoose) {
+		if _, err := strconv.ParseUint(m.TargetVersion, 10, 64); err != nil {
+			return fmt.Errorf("migrations.targetVersion must be numeric for engine=%s (got %q)", engine, m.TargetVersion)
+		}
+	}
+
+	// Rollbacks must be opted into and need an engine with a down path
+	if m.AllowDowngrade {
+		if engine == MigrationEngineFlyway {
+			return fmt.Errorf("migrations.allowDowngrade is not supported with engine=flyway")
+		}
+		if engine == MigrationEngineAtlas && (m.Atlas == nil || m.Atlas.DevURLSecretRef == nil) {
+			return fmt.Errorf("migrations.atlas.devURLSecretRef is required when allowDowngrade is set with engine=atlas")
+		}
+	}
+	if r.IsDowngrade() && !m.AllowDowngrade {
+		return fmt.Errorf("migrations.targetVersion %s is lower than the applied version %s; set migrations.allowDowngrade=true to roll back",
+			m.TargetVersion, r.Status.CurrentVersion)
+	}
+
+	if err := validateMigrationsSource(m); err != nil {
+		return err
+	}
+
+	for _, ref := range m.ImagePullSecrets {
+		if ref.Name == "" {
+			return fmt.Errorf("migrations.imagePullSecrets[].name cannot be empty")
+		}
+	}
+	if m.ECR != nil {
+		if m.ECR.RoleArn == "" {
+			return fmt.Errorf("migrations.ecr.roleArn is required when ecr is specified")
+		}
+		registry, _, _ := strings.Cut(m.ArtifactReference(), "/")
+		if !strings.Contains(registry, ".dkr.ecr.") {
+			return fmt.Errorf("migrations.ecr requires a private ECR image (<account>.dkr.ecr.<region>.amazonaws.com/...), got %q", m.ArtifactReference())
+		}
+	}
+	if m.Cosign != nil {
+		ref := m.Cosign.PublicKeySecretRef
+		if ref.Name == "" || ref.Key == "" {
+			return fmt.Errorf("migrations.cosign.publicKeySecretRef requires name and key")
+		}
+	}
+
+	// golang-migrate and goose only take the connection from args or env vars
+	if r.Spec.Runner != nil && r.Spec.Runner.ConnectionMode == ConnectionModeFile &&
+		(engine == MigrationEngineGolangMigrate || engine == MigrationEngineGoose) {
+		return fmt.Errorf("runner.connectionMode=File is not supported with engine=%s", engine)
+	}
+
+	if engine == MigrationEngineLiquibase {
+		if m.Liquibase == nil || m.Liquibase.ChangeLogFile == "" {
+			return fmt.Errorf("migrations.liquibase.changeLogFile is required when engine=liquibase")
+		}
+		if strings.HasPrefix(m.Liquibase.ChangeLogFile, "/") || strings.Contains(m.Liquibase.ChangeLogFile, "..") {
+			return fmt.Errorf("migrations.liquibase.changeLogFile must be a relative path inside migrations.dir")
+		}
+	}
+
+	return nil
+}
+
+// validateMigrationsSource ensures the files come from exactly one of image
+// and source, and that registry settings are only used with a registry
+func validateMigrationsSource(m MigrationsSpec) error {
+	if m.Source == nil {
+		if m.Image == "" {
+			return fmt.Errorf("migrations.image or migrations.source is required")
+		}
+		return nil
+	}
+	if m.Image != "" {
+		return fmt.Errorf("migrations.image and migrations.source are mutually exclusive")
+	}
+
+	s := m.Source
+	set := 0
+	for _, ok := range []bool{s.ConfigMap != nil, s.Secret != nil, s.PersistentVolumeClaim != nil, s.OCI != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("migrations.source requires exactly one of configMap, secret, persistentVolumeClaim or oci")
+	}
+
+	switch {
+	case s.ConfigMap != nil && s.ConfigMap.Name == "":
+		return fmt.Errorf("migrations.source.configMap.name is required")
+	case s.Secret != nil && s.Secret.Name == "":
+		return fmt.Errorf("migrations.source.secret.name is required")
+	case s.PersistentVolumeClaim != nil && s.PersistentVolumeClaim.ClaimName == "":
+		return fmt.Errorf("migrations.source.persistentVolumeClaim.claimName is required")
+	case s.OCI != nil && s.OCI.Reference == "":
+		return fmt.Errorf("migrations.source.oci.reference is required")
+	}
+
+	if s.OCI == nil && (len(m.ImagePullSecrets) > 0 || m.ECR != nil || m.Cosign != nil) {
+		return fmt.Errorf("migrations.imagePullSecrets, ecr and cosign require migrations.image or source.oci")
 	}
 	return nil
 }
@@ -116,9 +313,25 @@ func (r *DBUpgrade) validateDatabase() error {
 	dbType := r.Spec.Database.Type
 	hasAWS := r.Spec.Database.AWS != nil
 	hasConnection := r.Spec.Database.Connection != nil
+	hasVault := r.Spec.Database.Vault != nil
+
+	switch r.Spec.Database.Engine {
+	case "", DatabaseEnginePostgres, DatabaseEngineMySQL, DatabaseEngineMariaDB:
+	default:
+		return fmt.Errorf("database.engine must be one of postgres, mysql, mariadb (got %s)", r.Spec.Database.Engine)
+	}
+
+	// Aurora has PostgreSQL and MySQL editions only
+	if dbType == DatabaseTypeAWSAurora && r.Spec.Database.Engine == DatabaseEngineMariaDB {
+		return fmt.Errorf("database.engine=mariadb is not available for awsAurora")
+	}
 
 	switch dbType {
 	case DatabaseTypeAWSRDS, DatabaseTypeAWSAurora:
+		if hasVault {
+			return fmt.Errorf("database.vault is only supported for selfHosted type")
+		}
+
 		// For AWS types, must have AWS config OR connection secret
 		if !hasAWS && !hasConnection {
 			return fmt.Errorf("database.type=%s requires either aws or connection to be set", dbType)
@@ -144,9 +357,17 @@ func (r *DBUpgrade) validateDatabase() error {
 		}
 
 	case DatabaseTypeSelfHosted:
-		// Self-hosted must have connection secret
-		if !hasConnection {
-			return fmt.Errorf("database.type=selfHosted requires connection to be set")
+		// Self-hosted must have connection secret or Vault credentials
+		if !hasConnection && !hasVault {
+			return fmt.Errorf("database.type=selfHosted requires connection or vault to be set")
+		}
+		if hasConnection && hasVault {
+			return fmt.Errorf("database.connection and database.vault are mutually exclusive")
+		}
+		if hasVault {
+			if err := validateVault(r.Spec.Database.Vault); err != nil {
+				return err
+			}
 		}
 
 		// Warn if AWS config is set for self-hosted
@@ -155,19 +376,133 @@ func (r *DBUpgrade) validateDatabase() error {
 		}
 	}
 
-	// Validate connection secret if provided
+	// Validate connection if provided
 	if hasConnection {
-		if r.Spec.Database.Connection.URLSecretRef == nil {
-			return fmt.Errorf("database.connection.urlSecretRef is required when connection is specified")
+		if err := validateConnection(r.Spec.Database.Connection); err != nil {
+			return err
+		}
+	}
+
+	if r.Spec.Database.TLS != nil {
+		if err := r.validateDatabaseTLS(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateDatabaseTLS ensures exactly one CA source is set and that the
+// migration engine can use the mounted files
+func (r *DBUpgrade) validateDatabaseTLS() error {
+	t := r.Spec.Database.TLS
+
+	caSources := 0
+	for _, set := range []bool{t.CAConfigMapRef != nil, t.CASecretRef != nil, t.UseRDSCA} {
+		if set {
+			caSources++
+		}
+	}
+	if caSources != 1 {
+		return fmt.Errorf("database.tls requires exactly one of caConfigMapRef, caSecretRef, useRDSCA")
+	}
+	if t.CAConfigMapRef != nil && (t.CAConfigMapRef.Name == "" || t.CAConfigMapRef.Key == "") {
+		return fmt.Errorf("database.tls.caConfigMapRef name and key are required")
+	}
+	if t.CASecretRef != nil && (t.CASecretRef.Name == "" || t.CASecretRef.Key == "") {
+		return fmt.Errorf("database.tls.caSecretRef name and key are required")
+	}
+	if t.ClientCertSecretRef != nil && t.ClientCertSecretRef.Name == "" {
+		return fmt.Errorf("database.tls.clientCertSecretRef.name cannot be empty")
+	}
+	if t.UseRDSCA && r.Spec.Database.Type == DatabaseTypeSelfHosted {
+		return fmt.Errorf("database.tls.useRDSCA is only supported for awsRds and awsAurora")
+	}
+
+	// pgjdbc only reads PKCS-8 DER or PKCS-12 keys, not the PEM key of a
+	// kubernetes.io/tls Secret
+	switch r.Spec.Migrations.Engine {
+	case MigrationEngineFlyway, MigrationEngineLiquibase:
+		if t.ClientCertSecretRef != nil {
+			return fmt.Errorf("database.tls.clientCertSecretRef is not supported with migrations.engine=%s", r.Spec.Migrations.Engine)
 		}
-		if r.Spec.Database.Connection.URLSecretRef.Name == "" {
+	}
+
+	// Only golang-migrate reads CA and client certificate files for MySQL
+	// (x-tls-* URL parameters); the other runners' drivers have no equivalent
+	switch r.Spec.Database.Engine {
+	case DatabaseEngineMySQL, DatabaseEngineMariaDB:
+		if r.Spec.Migrations.Engine != MigrationEngineGolangMigrate {
+			return fmt.Errorf("database.tls with database.engine=%s requires migrations.engine=golang-migrate", r.Spec.Database.Engine)
+		}
+		if t.Mode == DatabaseTLSVerifyCA {
+			return fmt.Errorf("database.tls.mode=verify-ca is not supported for database.engine=%s", r.Spec.Database.Engine)
+		}
+	}
+
+	return nil
+}
+
+// validateConnection ensures exactly one of urlSecretRef and the structured
+// fields is used, and that the structured form can be built into a URL
+func validateConnection(c *ConnectionSpec) error {
+	hasStructured := c.Host != "" || c.Port != 0 || c.Database != "" || c.Username != "" ||
+		c.PasswordSecretRef != nil || c.SSLMode != "" || len(c.Params) > 0
+
+	if c.URLSecretRef != nil && hasStructured {
+		return fmt.Errorf("database.connection.urlSecretRef and the structured connection fields (host, port, database, username, passwordSecretRef, sslMode, params) are mutually exclusive")
+	}
+
+	if c.URLSecretRef != nil {
+		if c.URLSecretRef.Name == "" {
 			return fmt.Errorf("database.connection.urlSecretRef.name cannot be empty")
 		}
-		if r.Spec.Database.Connection.URLSecretRef.Key == "" {
+		if c.URLSecretRef.Key == "" {
 			return fmt.Errorf("database.connection.urlSecretRef.key cannot be empty")
 		}
+		return nil
 	}
 
+	if !hasStructured {
+		return fmt.Errorf("database.connection.urlSecretRef is required when connection is specified, unless host, database, username and passwordSecretRef are set")
+	}
+	if c.Host == "" {
+		return fmt.Errorf("database.connection.host is required when urlSecretRef is not set")
+	}
+	if c.Database == "" {
+		return fmt.Errorf("database.connection.database is required when urlSecretRef is not set")
+	}
+	if c.Username == "" {
+		return fmt.Errorf("database.connection.username is required when urlSecretRef is not set")
+	}
+	if c.PasswordSecretRef == nil || c.PasswordSecretRef.Name == "" || c.PasswordSecretRef.Key == "" {
+		return fmt.Errorf("database.connection.passwordSecretRef name and key are required when urlSecretRef is not set")
+	}
+	return nil
+}
+
+// validateVault ensures the fields needed to log in and build the URL are set.
+// The address must be https: the ServiceAccount token and the issued database
+// credentials are sent over it.
+func validateVault(v *VaultSpec) error {
+	switch {
+	case strings.HasPrefix(v.Address, "https://"):
+	case strings.HasPrefix(v.Address, "http://") && AllowInsecureVault:
+	default:
+		return fmt.Errorf("database.vault.address must be an https URL")
+	}
+	if v.AuthRole == "" {
+		return fmt.Errorf("database.vault.authRole is required when vault is specified")
+	}
+	if v.Role == "" {
+		return fmt.Errorf("database.vault.role is required when vault is specified")
+	}
+	if v.Host == "" {
+		return fmt.Errorf("database.vault.host is required when vault is specified")
+	}
+	if v.DBName == "" {
+		return fmt.Errorf("database.vault.dbName is required when vault is specified")
+	}
 	return nil
 }
 
@@ -192,48 +527,171 @@ func (r *DBUpgrade) validateMetrics() error {
 
 // validateMetricCheck validates a single metric check
 func validateMetricCheck(m MetricCheck) error {
-	// Validate that target type matches target configuration
-	switch m.Target.Type {
+	if m.Source == MetricSourcePrometheus {
+		if m.Prometheus == nil {
+			return fmt.Errorf("source=Prometheus requires prometheus to be set")
+		}
+		if m.Target != nil || m.MetricName != "" {
+			return fmt.Errorf("source=Prometheus should not have metricName or target set")
+		}
+	} else {
+		if m.Prometheus != nil {
+			return fmt.Errorf("prometheus is only supported with source=Prometheus")
+		}
+		if m.MetricName == "" || m.Target == nil {
+			return fmt.Errorf("source=Custom and source=External require metricName and target to be set")
+		}
+		if err := validateMetricTarget(m.Target); err != nil {
+			return err
+		}
+	}
+
+	// Validate threshold value is not empty
+	if m.Threshold.Value.IsZero() {
+		return fmt.Errorf("threshold.value cannot be empty")
+	}
+
+	return nil
+}
+
+// validateMetricTarget validates that the target type matches the target configuration
+func validateMetricTarget(t *MetricTarget) error {
+	switch t.Type {
 	case MetricTargetTypePods:
-		if m.Target.Pods == nil {
+		if t.Pods == nil {
 			return fmt.Errorf("target.type=Pods requires target.pods to be set")
 		}
-		if m.Target.Object != nil {
+		if t.Object != nil {
 			return fmt.Errorf("target.type=Pods should not have target.object set")
 		}
-		if m.Target.External != nil {
+		if t.External != nil {
 			return fmt.Errorf("target.type=Pods should not have target.external set")
 		}
 
 	case MetricTargetTypeObject:
-		if m.Target.Object == nil {
+		if t.Object == nil {
 			return fmt.Errorf("target.type=Object requires target.object to be set")
 		}
-		if m.Target.Pods != nil {
+		if t.Pods != nil {
 			return fmt.Errorf("target.type=Object should not have target.pods set")
 		}
-		if m.Target.External != nil {
+		if t.External != nil {
 			return fmt.Errorf("target.type=Object should not have target.external set")
 		}
 
 	case MetricTargetTypeExternal:
 		// External can have selector (optional)
-		if m.Target.Pods != nil {
+		if t.Pods != nil {
 			return fmt.Errorf("target.type=External should not have target.pods set")
 		}
-		if m.Target.Object != nil {
+		if t.Object != nil {
 			return fmt.Errorf("target.type=External should not have target.object set")
 		}
 	}
 
-	// Validate threshold value is not empty
-	if m.Threshold.Value.IsZero() {
-		return fmt.Errorf("threshold.value cannot be empty")
+	return nil
+}
+
+// validateHTTPChecks validates HTTP check configurations
+func (r *DBUpgrade) validateHTTPChecks() error {
+	for _, check := range r.Spec.Checks.Pre.HTTP {
+		if err := validateHTTPCheck(check); err != nil {
+			return fmt.Errorf("pre-check http %q: %w", check.Name, err)
+		}
+		if check.BakeSeconds > 0 {
+			return fmt.Errorf("pre-check http %q: bakeSeconds is only supported for postchecks", check.Name)
+		}
+	}
+
+	for _, check := range r.Spec.Checks.Post.HTTP {
+		if err := validateHTTPCheck(check); err != nil {
+			return fmt.Errorf("post-check http %q: %w", check.Name, err)
+		}
 	}
 
 	return nil
 }
 
+// validateHTTPCheck validates the target, headers and response checks
+func validateHTTPCheck(c HTTPCheck) error {
+	if (c.URL == "") == (c.Service == nil) {
+		return fmt.Errorf("exactly one of url and service must be set")
+	}
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("url must be an absolute http(s) URL")
+		}
+	}
+
+	for _, h := range c.Headers {
+		if (h.Value == "") == (h.ValueSecretRef == nil) {
+			return fmt.Errorf("header %q must set exactly one of value and valueSecretRef", h.Name)
+		}
+	}
+	if c.Body != "" && c.Method != http.MethodPost {
+		return fmt.Errorf("body is only supported with method POST")
+	}
+
+	for _, code := range c.ExpectedStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("expectedStatusCodes must be between 100 and 599, got %d", code)
+		}
+	}
+
+	if (c.JSONPath == "") != (c.Threshold == nil) {
+		return fmt.Errorf("jsonPath and threshold must be set together")
+	}
+	if c.JSONPath != "" {
+		if c.Method == http.MethodHead {
+			return fmt.Errorf("jsonPath is not supported with method HEAD")
+		}
+		if err := jsonpath.New(c.Name).Parse(c.JSONPath); err != nil {
+			return fmt.Errorf("invalid jsonPath: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateSQLChecks validates SQL check configurations
+func (r *DBUpgrade) validateSQLChecks() error {
+	for _, check := range r.Spec.Checks.Pre.SQL {
+		if err := validateSQLCheck(check); err != nil {
+			return fmt.Errorf("pre-check sql %q: %w", check.Name, err)
+		}
+		if check.BakeSeconds > 0 {
+			return fmt.Errorf("pre-check sql %q: bakeSeconds is only supported for postchecks", check.Name)
+		}
+	}
+
+	for _, check := range r.Spec.Checks.Post.SQL {
+		if err := validateSQLCheck(check); err != nil {
+			return fmt.Errorf("post-check sql %q: %w", check.Name, err)
+		}
+	}
+
+	// The Vault lease is revoked as soon as the migration Job finishes
+	if len(r.Spec.Checks.Post.SQL) > 0 && r.Spec.Database.Vault != nil {
+		return fmt.Errorf("checks.post.sql is not supported with database.vault: credentials are revoked when the migration finishes")
+	}
+
+	return nil
+}
+
+// validateSQLCheck ensures the query is a single statement for the check client
+func validateSQLCheck(c SQLCheck) error {
+	query := strings.TrimSpace(c.Query)
+	if strings.Contains(strings.TrimRight(query, "; \t\n"), ";") {
+		return fmt.Errorf("query must be a single statement")
+	}
+	// psql would run backslash meta-commands
+	if strings.HasPrefix(query, "\\") {
+		return fmt.Errorf("query must be a SQL statement, not a client command")
+	}
+	return nil
+}
+
 // validateNotProgressing blocks spec changes while a migration is running.
 // This prevents partial migration state where a migration is interrupted.
 // Note: The controller also has this guard for defense in depth.
@@ -263,22 +721,34 @@ func (r *DBUpgrade) validateImmutableFields(old *DBUpgrade) error {
 			old.Spec.Database.Type, r.Spec.Database.Type)
 	}
 
-	// Connection secret reference is immutable
-	oldHasConnection := old.Spec.Database.Connection != nil && old.Spec.Database.Connection.URLSecretRef != nil
-	newHasConnection := r.Spec.Database.Connection != nil && r.Spec.Database.Connection.URLSecretRef != nil
+	// Database engine is immutable
+	if r.Spec.Database.Engine != old.Spec.Database.Engine {
+		return fmt.Errorf("database.engine is immutable (cannot change from %s to %s)",
+			old.Spec.Database.Engine, r.Spec.Database.Engine)
+	}
+
+	// Connection is immutable
+	oldConn := old.Spec.Database.Connection
+	newConn := r.Spec.Database.Connection
 
-	if oldHasConnection != newHasConnection {
+	if (oldConn != nil) != (newConn != nil) {
 		return fmt.Errorf("database.connection cannot be added or removed after creation")
 	}
 
-	if oldHasConnection && newHasConnection {
-		oldRef := old.Spec.Database.Connection.URLSecretRef
-		newRef := r.Spec.Database.Connection.URLSecretRef
+	if oldConn != nil && newConn != nil {
+		oldRef := oldConn.URLSecretRef
+		newRef := newConn.URLSecretRef
 
-		if oldRef.Name != newRef.Name || oldRef.Key != newRef.Key {
+		if (oldRef != nil) != (newRef != nil) {
+			return fmt.Errorf("database.connection cannot switch between urlSecretRef and structured fields after creation")
+		}
+		if oldRef != nil && (oldRef.Name != newRef.Name || oldRef.Key != newRef.Key) {
 			return fmt.Errorf("database.connection.urlSecretRef is immutable (cannot change from %s/%s to %s/%s)",
 				oldRef.Name, oldRef.Key, newRef.Name, newRef.Key)
 		}
+		if !reflect.DeepEqual(oldConn, newConn) {
+			return fmt.Errorf("database.connection is immutable")
+		}
 	}
 
 	// AWS configuration is immutable
@@ -24,6 +24,28 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// newDBUpgrade returns a selfHosted DBUpgrade with the given migrations spec
+func newDBUpgrade(migrations MigrationsSpec) *DBUpgrade {
+	return &DBUpgrade{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-engine",
+			Namespace: "default",
+		},
+		Spec: DBUpgradeSpec{
+			Migrations: migrations,
+			Database: DatabaseSpec{
+				Type: DatabaseTypeSelfHosted,
+				Connection: &ConnectionSpec{
+					URLSecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "db-secret"},
+						Key:                  "url",
+					},
+				},
+			},
+		},
+	}
+}
+
 var _ = Describe("DBUpgrade Webhook", func() {
 	Context("Database Validation", func() {
 		It("should accept selfHosted with connection secret", func() {
@@ -53,75 +75,737 @@ var _ = Describe("DBUpgrade Webhook", func() {
 			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
 		})
 
-		It("should reject selfHosted without connection", func() {
-			dbUpgrade := &DBUpgrade{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-invalid-selfhosted",
-					Namespace: "default",
+		It("should reject selfHosted without connection", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-invalid-selfhosted",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						// Missing connection - should fail
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires connection"))
+		})
+
+		It("should accept selfHosted with structured connection", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-structured",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Connection: &ConnectionSpec{
+							Host:     "postgres.db.svc",
+							Database: "app",
+							Username: "migrator",
+							PasswordSecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: "db-password",
+								},
+								Key: "password",
+							},
+							SSLMode: "verify-full",
+						},
+					},
+				},
+			}
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject urlSecretRef combined with structured fields", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-structured-and-url",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Connection: &ConnectionSpec{
+							URLSecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: "db-secret",
+								},
+								Key: "url",
+							},
+							Host: "postgres.db.svc",
+						},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mutually exclusive"))
+		})
+
+		It("should reject structured connection without passwordSecretRef", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-structured-no-password",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Connection: &ConnectionSpec{
+							Host:     "postgres.db.svc",
+							Database: "app",
+							Username: "migrator",
+						},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("passwordSecretRef"))
+		})
+
+		It("should accept selfHosted with Vault credentials", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-vault",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Vault: &VaultSpec{
+							Address:  "https://vault.vault.svc:8200",
+							AuthRole: "migrator",
+							Role:     "app-migrations",
+							Host:     "postgres.db.svc",
+							DBName:   "app",
+						},
+					},
+				},
+			}
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject both connection and Vault", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-vault-and-connection",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Connection: &ConnectionSpec{
+							URLSecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: "db-secret",
+								},
+								Key: "url",
+							},
+						},
+						Vault: &VaultSpec{
+							Address:  "https://vault.vault.svc:8200",
+							AuthRole: "migrator",
+							Role:     "app-migrations",
+							Host:     "postgres.db.svc",
+							DBName:   "app",
+						},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mutually exclusive"))
+		})
+
+		It("should reject an http Vault address unless insecure Vault is allowed", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-vault-http",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Vault: &VaultSpec{
+							Address:  "http://vault.vault.svc:8200",
+							AuthRole: "migrator",
+							Role:     "app-migrations",
+							Host:     "postgres.db.svc",
+							DBName:   "app",
+						},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must be an https URL"))
+
+			AllowInsecureVault = true
+			DeferCleanup(func() { AllowInsecureVault = false })
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should accept awsRds with AWS config", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-aws",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeAWSRDS,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							Port:     5432,
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+					},
+				},
+			}
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should accept awsRds with the RDS CA bundle", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-aws-tls",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeAWSRDS,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+						TLS: &DatabaseTLSSpec{UseRDSCA: true},
+					},
+				},
+			}
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject database.tls with more than one CA source", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-tls-two-cas",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeAWSRDS,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+						TLS: &DatabaseTLSSpec{
+							UseRDSCA: true,
+							CASecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "db-ca"},
+								Key:                  "ca.crt",
+							},
+						},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exactly one of caConfigMapRef, caSecretRef, useRDSCA"))
+		})
+
+		It("should reject database.tls for mysql with a runner that cannot read the CA", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-tls-mysql-atlas",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type:   DatabaseTypeAWSRDS,
+						Engine: DatabaseEngineMySQL,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+						TLS: &DatabaseTLSSpec{UseRDSCA: true},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires migrations.engine=golang-migrate"))
+		})
+
+		It("should reject a client certificate with a JDBC runner", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-tls-client-cert-flyway",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image:  "test:v1",
+						Engine: MigrationEngineFlyway,
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeAWSRDS,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+						TLS: &DatabaseTLSSpec{
+							UseRDSCA:            true,
+							ClientCertSecretRef: &corev1.LocalObjectReference{Name: "db-client-cert"},
+						},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("clientCertSecretRef is not supported with migrations.engine=flyway"))
+
+			dbUpgrade.Spec.Database.TLS.ClientCertSecretRef = nil
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should accept awsRds with the mysql engine", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-aws-mysql",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type:   DatabaseTypeAWSRDS,
+						Engine: DatabaseEngineMySQL,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+					},
+				},
+			}
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject mariadb on awsAurora", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-aurora-mariadb",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type:   DatabaseTypeAWSAurora,
+						Engine: DatabaseEngineMariaDB,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mariadb is not available for awsAurora"))
+		})
+
+		It("should reject awsRds without AWS config or connection", func() {
+			dbUpgrade := &DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-invalid-aws",
+					Namespace: "default",
+				},
+				Spec: DBUpgradeSpec{
+					Migrations: MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: DatabaseSpec{
+						Type: DatabaseTypeAWSRDS,
+						// Missing both aws and connection - should fail
+					},
+				},
+			}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires either aws or connection"))
+		})
+	})
+
+	Context("Migration Engine Validation", func() {
+		It("should accept flyway with flyway settings", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:  "test:v1",
+				Engine: MigrationEngineFlyway,
+				Flyway: &FlywaySpec{BaselineOnMigrate: true},
+			})
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject atlas settings with a different engine", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:  "test:v1",
+				Engine: MigrationEngineGoose,
+				Atlas:  &AtlasSpec{RevisionsSchema: "atlas"},
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("migrations.atlas is only valid with engine=atlas"))
+		})
+
+		It("should reject liquibase without changeLogFile", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:  "test:v1",
+				Engine: MigrationEngineLiquibase,
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("changeLogFile is required"))
+		})
+
+		It("should reject liquibase changeLogFile outside the migrations dir", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:     "test:v1",
+				Engine:    MigrationEngineLiquibase,
+				Liquibase: &LiquibaseSpec{ChangeLogFile: "../changelog.xml"},
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must be a relative path"))
+		})
+
+		It("should accept connectionMode=File with flyway", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:  "test:v1",
+				Engine: MigrationEngineFlyway,
+			})
+			dbUpgrade.Spec.Runner = &RunnerSpec{ConnectionMode: ConnectionModeFile}
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject connectionMode=File with goose", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:  "test:v1",
+				Engine: MigrationEngineGoose,
+			})
+			dbUpgrade.Spec.Runner = &RunnerSpec{ConnectionMode: ConnectionModeFile}
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("runner.connectionMode=File is not supported"))
+		})
+
+		It("should accept Plan mode with atlas", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{Image: "test:v1"})
+			dbUpgrade.Spec.Mode = MigrationModePlan
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject Plan mode with golang-migrate", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:  "test:v1",
+				Engine: MigrationEngineGolangMigrate,
+			})
+			dbUpgrade.Spec.Mode = MigrationModePlan
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mode=Plan is not supported"))
+		})
+
+		It("should accept a non-numeric targetVersion with flyway", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:         "test:v1",
+				Engine:        MigrationEngineFlyway,
+				TargetVersion: "2.1",
+			})
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject a non-numeric targetVersion with goose", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:         "test:v1",
+				Engine:        MigrationEngineGoose,
+				TargetVersion: "v2",
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("targetVersion must be numeric"))
+		})
+	})
+
+	Context("Migrations Source Validation", func() {
+		It("should accept a ConfigMap source without an image", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Source: &MigrationsSourceSpec{ConfigMap: &corev1.LocalObjectReference{Name: "app-migrations"}},
+			})
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject neither image nor source", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("migrations.image or migrations.source is required"))
+		})
+
+		It("should reject image combined with source", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:  "test:v1",
+				Source: &MigrationsSourceSpec{OCI: &OCISourceSpec{Reference: "ghcr.io/acme/sql:v1"}},
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mutually exclusive"))
+		})
+
+		It("should reject two sources", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Source: &MigrationsSourceSpec{
+					ConfigMap:             &corev1.LocalObjectReference{Name: "app-migrations"},
+					PersistentVolumeClaim: &PVCSourceSpec{ClaimName: "migrations"},
 				},
-				Spec: DBUpgradeSpec{
-					Migrations: MigrationsSpec{
-						Image: "test:v1",
-					},
-					Database: DatabaseSpec{
-						Type: DatabaseTypeSelfHosted,
-						// Missing connection - should fail
-					},
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exactly one of"))
+		})
+
+		It("should reject registry settings with a PVC source", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Source:           &MigrationsSourceSpec{PersistentVolumeClaim: &PVCSourceSpec{ClaimName: "migrations"}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "ghcr-pull"}},
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("require migrations.image or source.oci"))
+		})
+	})
+
+	Context("Registry Auth Validation", func() {
+		It("should accept ecr with a private ECR image", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image: "123456789012.dkr.ecr.us-east-1.amazonaws.com/app/migrations:v1",
+				ECR:   &ECRAuthSpec{RoleArn: "arn:aws:iam::123456789012:role/ecr-pull"},
+			})
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject ecr with a non-ECR image", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image: "ghcr.io/acme/migrations:v1",
+				ECR:   &ECRAuthSpec{RoleArn: "arn:aws:iam::123456789012:role/ecr-pull"},
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires a private ECR image"))
+		})
+
+		It("should reject an imagePullSecret without a name", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image:            "ghcr.io/acme/migrations:v1",
+				ImagePullSecrets: []corev1.LocalObjectReference{{}},
+			})
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("imagePullSecrets[].name cannot be empty"))
+		})
+
+		It("should reject cosign without a public key secret key", func() {
+			dbUpgrade := newDBUpgrade(MigrationsSpec{
+				Image: "ghcr.io/acme/migrations:v1",
+				Cosign: &CosignVerificationSpec{
+					PublicKeySecretRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "cosign-pub"}},
 				},
-			}
+			})
 
 			err := dbUpgrade.validateDBUpgrade()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("requires connection"))
+			Expect(err.Error()).To(ContainSubstring("publicKeySecretRef requires name and key"))
 		})
+	})
 
-		It("should accept awsRds with AWS config", func() {
-			dbUpgrade := &DBUpgrade{
+	Context("Downgrade Validation", func() {
+		newDowngrade := func(migrations MigrationsSpec, currentVersion string) *DBUpgrade {
+			return &DBUpgrade{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-aws",
+					Name:      "test-downgrade",
 					Namespace: "default",
 				},
 				Spec: DBUpgradeSpec{
-					Migrations: MigrationsSpec{
-						Image: "test:v1",
-					},
+					Migrations: migrations,
 					Database: DatabaseSpec{
-						Type: DatabaseTypeAWSRDS,
-						AWS: &AWSSpec{
-							RoleArn:  "arn:aws:iam::123456789012:role/test",
-							Region:   "us-east-1",
-							Host:     "db.amazonaws.com",
-							Port:     5432,
-							DBName:   "testdb",
-							Username: "testuser",
+						Type: DatabaseTypeSelfHosted,
+						Connection: &ConnectionSpec{
+							URLSecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "db-secret"},
+								Key:                  "url",
+							},
 						},
 					},
 				},
+				Status: DBUpgradeStatus{CurrentVersion: currentVersion},
 			}
+		}
+
+		It("should reject a lower targetVersion without allowDowngrade", func() {
+			dbUpgrade := newDowngrade(MigrationsSpec{
+				Image:         "test:v1",
+				Engine:        MigrationEngineGoose,
+				TargetVersion: "3",
+			}, "5")
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("set migrations.allowDowngrade=true"))
+		})
+
+		It("should accept a lower targetVersion with allowDowngrade", func() {
+			dbUpgrade := newDowngrade(MigrationsSpec{
+				Image:          "test:v1",
+				Engine:         MigrationEngineGoose,
+				TargetVersion:  "3",
+				AllowDowngrade: true,
+			}, "5")
 
 			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
 		})
 
-		It("should reject awsRds without AWS config or connection", func() {
-			dbUpgrade := &DBUpgrade{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-invalid-aws",
-					Namespace: "default",
-				},
-				Spec: DBUpgradeSpec{
-					Migrations: MigrationsSpec{
-						Image: "test:v1",
-					},
-					Database: DatabaseSpec{
-						Type: DatabaseTypeAWSRDS,
-						// Missing both aws and connection - should fail
-					},
-				},
-			}
+		It("should accept a higher targetVersion without allowDowngrade", func() {
+			dbUpgrade := newDowngrade(MigrationsSpec{
+				Image:         "test:v1",
+				Engine:        MigrationEngineGoose,
+				TargetVersion: "10",
+			}, "9")
+
+			Expect(dbUpgrade.validateDBUpgrade()).To(Succeed())
+		})
+
+		It("should reject allowDowngrade with flyway", func() {
+			dbUpgrade := newDowngrade(MigrationsSpec{
+				Image:          "test:v1",
+				Engine:         MigrationEngineFlyway,
+				AllowDowngrade: true,
+			}, "")
 
 			err := dbUpgrade.validateDBUpgrade()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("requires either aws or connection"))
+			Expect(err.Error()).To(ContainSubstring("not supported with engine=flyway"))
+		})
+
+		It("should require a dev URL for atlas downgrades", func() {
+			dbUpgrade := newDowngrade(MigrationsSpec{
+				Image:          "test:v1",
+				AllowDowngrade: true,
+			}, "")
+
+			err := dbUpgrade.validateDBUpgrade()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("devURLSecretRef is required"))
+		})
+
+		It("should order migration versions numerically", func() {
+			Expect(CompareMigrationVersions("20240102", "20240101")).To(Equal(1))
+			Expect(CompareMigrationVersions("2.9", "2.10")).To(Equal(-1))
+			Expect(CompareMigrationVersions("v1.2", "v1.2")).To(Equal(0))
+			Expect(CompareMigrationVersions("0010", "9")).To(Equal(1))
+			Expect(CompareMigrationVersions("1.2", "1.2.1")).To(Equal(-1))
 		})
 	})
 
@@ -130,7 +814,7 @@ var _ = Describe("DBUpgrade Webhook", func() {
 			metric := MetricCheck{
 				Name:       "test-metric",
 				MetricName: "cpu_usage",
-				Target: MetricTarget{
+				Target: &MetricTarget{
 					Type: MetricTargetTypePods,
 					Pods: &PodsTarget{
 						Selector: metav1.LabelSelector{
@@ -151,7 +835,7 @@ var _ = Describe("DBUpgrade Webhook", func() {
 			metric := MetricCheck{
 				Name:       "test-metric",
 				MetricName: "cpu_usage",
-				Target: MetricTarget{
+				Target: &MetricTarget{
 					Type: MetricTargetTypePods,
 					// Missing Pods field - should fail
 				},
@@ -170,7 +854,7 @@ var _ = Describe("DBUpgrade Webhook", func() {
 			metric := MetricCheck{
 				Name:       "test-metric",
 				MetricName: "cpu_usage",
-				Target: MetricTarget{
+				Target: &MetricTarget{
 					Type: MetricTargetTypePods,
 					Pods: &PodsTarget{
 						Selector: metav1.LabelSelector{
@@ -187,6 +871,258 @@ var _ = Describe("DBUpgrade Webhook", func() {
 			err := validateMetricCheck(metric)
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("should accept a Prometheus query without metricName or target", func() {
+			metric := MetricCheck{
+				Name:   "error-rate",
+				Source: MetricSourcePrometheus,
+				Prometheus: &PrometheusQuery{
+					URL:   "http://prometheus-operated.monitoring:9090",
+					Query: `sum(rate(http_requests_total{code=~"5.."}[5m]))`,
+				},
+				Threshold: ThresholdSpec{
+					Operator: ThresholdOperatorLT,
+					Value:    resource.MustParse("0.05"),
+				},
+			}
+
+			Expect(validateMetricCheck(metric)).To(Succeed())
+
+			metric.Target = &MetricTarget{Type: MetricTargetTypeExternal}
+			err := validateMetricCheck(metric)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("should not have metricName or target"))
+		})
+
+		It("should reject source=Prometheus without a query", func() {
+			metric := MetricCheck{
+				Name:   "error-rate",
+				Source: MetricSourcePrometheus,
+				Threshold: ThresholdSpec{
+					Operator: ThresholdOperatorLT,
+					Value:    resource.MustParse("0.05"),
+				},
+			}
+
+			err := validateMetricCheck(metric)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires prometheus"))
+		})
+
+		It("should reject a Prometheus query on a Custom metric", func() {
+			metric := MetricCheck{
+				Name:       "error-rate",
+				Source:     MetricSourceCustom,
+				MetricName: "http_errors",
+				Target: &MetricTarget{
+					Type: MetricTargetTypeExternal,
+				},
+				Prometheus: &PrometheusQuery{
+					URL:   "http://prometheus-operated.monitoring:9090",
+					Query: "up",
+				},
+				Threshold: ThresholdSpec{
+					Operator: ThresholdOperatorLT,
+					Value:    resource.MustParse("0.05"),
+				},
+			}
+
+			err := validateMetricCheck(metric)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("only supported with source=Prometheus"))
+
+			metric.Prometheus = nil
+			metric.Target = nil
+			err = validateMetricCheck(metric)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("require metricName and target"))
+		})
+	})
+
+	Context("SQL Check Validation", func() {
+		sqlCheck := func(query string) SQLCheck {
+			return SQLCheck{
+				Name:  "legacy-orders",
+				Query: query,
+				Threshold: ThresholdSpec{
+					Operator: ThresholdOperatorLTE,
+					Value:    resource.MustParse("0"),
+				},
+			}
+		}
+
+		It("should accept a single statement with a trailing semicolon", func() {
+			Expect(validateSQLCheck(sqlCheck("SELECT count(*) FROM legacy_orders;"))).To(Succeed())
+		})
+
+		It("should reject multiple statements", func() {
+			err := validateSQLCheck(sqlCheck("SELECT 1; DELETE FROM legacy_orders"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("single statement"))
+		})
+
+		It("should reject psql meta-commands", func() {
+			err := validateSQLCheck(sqlCheck(`\copy legacy_orders TO '/tmp/orders.csv'`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not a client command"))
+		})
+
+		It("should reject bakeSeconds on a precheck", func() {
+			check := sqlCheck("SELECT count(*) FROM legacy_orders")
+			check.BakeSeconds = 60
+			dbUpgrade := &DBUpgrade{Spec: DBUpgradeSpec{Checks: &ChecksSpec{Pre: PreChecksSpec{SQL: []SQLCheck{check}}}}}
+
+			err := dbUpgrade.validateSQLChecks()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("only supported for postchecks"))
+		})
+
+		It("should reject postchecks with Vault credentials", func() {
+			dbUpgrade := &DBUpgrade{
+				Spec: DBUpgradeSpec{
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Vault: &VaultSpec{
+							Address:  "https://vault.vault.svc:8200",
+							AuthRole: "migrator",
+							Role:     "app-migrations",
+							Host:     "postgres.db.svc",
+							DBName:   "app",
+						},
+					},
+					Checks: &ChecksSpec{Post: PostChecksSpec{SQL: []SQLCheck{sqlCheck("SELECT count(*) FROM orders")}}},
+				},
+			}
+
+			err := dbUpgrade.validateSQLChecks()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not supported with database.vault"))
+		})
+	})
+
+	Context("HTTP Check Validation", func() {
+		httpCheck := func() HTTPCheck {
+			return HTTPCheck{
+				Name:    "ready-for-migration",
+				Service: &HTTPServiceRef{Name: "app", Port: 8080, Path: "/ready-for-migration"},
+				Method:  "GET",
+			}
+		}
+
+		It("should accept a Service check with a JSONPath threshold", func() {
+			check := httpCheck()
+			check.JSONPath = "{.pending}"
+			check.Threshold = &ThresholdSpec{Operator: ThresholdOperatorLTE, Value: resource.MustParse("0")}
+			check.Headers = []HTTPHeader{{Name: "Authorization", ValueSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "app-token"},
+				Key:                  "header",
+			}}}
+
+			Expect(validateHTTPCheck(check)).To(Succeed())
+		})
+
+		It("should require exactly one of url and service", func() {
+			check := httpCheck()
+			check.URL = "https://flags.example.com/api/flags/orders-v2"
+			err := validateHTTPCheck(check)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exactly one of url and service"))
+
+			check.Service = nil
+			Expect(validateHTTPCheck(check)).To(Succeed())
+		})
+
+		It("should require jsonPath and threshold together", func() {
+			check := httpCheck()
+			check.JSONPath = "{.pending}"
+			err := validateHTTPCheck(check)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("set together"))
+
+			check.Threshold = &ThresholdSpec{Operator: ThresholdOperatorLTE, Value: resource.MustParse("0")}
+			check.JSONPath = "{.pending"
+			err = validateHTTPCheck(check)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid jsonPath"))
+		})
+
+		It("should reject a header with both a value and a Secret", func() {
+			check := httpCheck()
+			check.Headers = []HTTPHeader{{Name: "X-Token", Value: "abc", ValueSecretRef: &corev1.SecretKeySelector{Key: "token"}}}
+			err := validateHTTPCheck(check)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exactly one of value and valueSecretRef"))
+		})
+
+		It("should reject a body without POST", func() {
+			check := httpCheck()
+			check.Body = `{"dryRun":true}`
+			err := validateHTTPCheck(check)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("only supported with method POST"))
+		})
+
+		It("should reject bakeSeconds on a precheck", func() {
+			check := httpCheck()
+			check.BakeSeconds = 60
+			dbUpgrade := &DBUpgrade{Spec: DBUpgradeSpec{Checks: &ChecksSpec{Pre: PreChecksSpec{HTTP: []HTTPCheck{check}}}}}
+
+			err := dbUpgrade.validateHTTPChecks()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("only supported for postchecks"))
+		})
+	})
+
+	Context("Workload Rollout Validation", func() {
+		rollout := func(check WorkloadRolloutCheck) *DBUpgrade {
+			return &DBUpgrade{Spec: DBUpgradeSpec{Checks: &ChecksSpec{Pre: PreChecksSpec{WorkloadRollout: []WorkloadRolloutCheck{check}}}}}
+		}
+
+		It("should accept workload references or a selector", func() {
+			Expect(rollout(WorkloadRolloutCheck{
+				Workloads: []WorkloadReference{{Kind: WorkloadKindDeployment, Name: "orders-api"}},
+			}).validateWorkloadRollout()).To(Succeed())
+			Expect(rollout(WorkloadRolloutCheck{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "orders"}},
+			}).validateWorkloadRollout()).To(Succeed())
+		})
+
+		It("should require exactly one of workloads and selector", func() {
+			err := rollout(WorkloadRolloutCheck{}).validateWorkloadRollout()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exactly one of workloads and selector"))
+
+			err = rollout(WorkloadRolloutCheck{
+				Workloads: []WorkloadReference{{Kind: WorkloadKindStatefulSet, Name: "orders-db"}},
+				Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "orders"}},
+			}).validateWorkloadRollout()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Pod Version Validation", func() {
+		podVersion := func(source string) *DBUpgrade {
+			return &DBUpgrade{Spec: DBUpgradeSpec{Checks: &ChecksSpec{Pre: PreChecksSpec{MinPodVersions: []MinPodVersionCheck{{
+				Selector:      metav1.LabelSelector{MatchLabels: map[string]string{"app": "orders"}},
+				MinVersion:    "2.0.0",
+				VersionSource: source,
+			}}}}}}
+		}
+
+		It("should accept each version source", func() {
+			for _, source := range []string{"", "imageTag", "imageLabel", "label:app.kubernetes.io/version", "annotation:example.com/release", "env:APP_VERSION"} {
+				Expect(podVersion(source).validateMinPodVersions()).To(Succeed(), source)
+			}
+		})
+
+		It("should reject an invalid label key or variable name", func() {
+			err := podVersion("label:not a key").validateMinPodVersions()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("minPodVersions[0].versionSource"))
+
+			Expect(podVersion("env:1VERSION").validateMinPodVersions()).NotTo(Succeed())
+			Expect(podVersion("imageDigest").validateMinPodVersions()).NotTo(Succeed())
+		})
 	})
 
 	Context("Immutability Validation", func() {
@@ -236,6 +1172,57 @@ var _ = Describe("DBUpgrade Webhook", func() {
 			Expect(err.Error()).To(ContainSubstring("database.connection.urlSecretRef is immutable"))
 		})
 
+		It("should reject changing the structured connection", func() {
+			old := &DBUpgrade{
+				Spec: DBUpgradeSpec{
+					Database: DatabaseSpec{
+						Type: DatabaseTypeSelfHosted,
+						Connection: &ConnectionSpec{
+							Host:     "postgres.db.svc",
+							Database: "app",
+							Username: "migrator",
+							PasswordSecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "db-password"},
+								Key:                  "password",
+							},
+						},
+					},
+				},
+			}
+
+			new := old.DeepCopy()
+			new.Spec.Database.Connection.Host = "other.db.svc"
+
+			err := new.validateImmutableFields(old)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("database.connection is immutable"))
+		})
+
+		It("should reject changing database.engine", func() {
+			old := &DBUpgrade{
+				Spec: DBUpgradeSpec{
+					Database: DatabaseSpec{
+						Type:   DatabaseTypeAWSRDS,
+						Engine: DatabaseEngineMySQL,
+						AWS: &AWSSpec{
+							RoleArn:  "arn:aws:iam::123456789012:role/test",
+							Region:   "us-east-1",
+							Host:     "db.amazonaws.com",
+							DBName:   "testdb",
+							Username: "testuser",
+						},
+					},
+				},
+			}
+
+			new := old.DeepCopy()
+			new.Spec.Database.Engine = DatabaseEnginePostgres
+
+			err := new.validateImmutableFields(old)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("database.engine is immutable"))
+		})
+
 		It("should reject changing database.aws.roleArn", func() {
 			old := &DBUpgrade{
 				Spec: DBUpgradeSpec{
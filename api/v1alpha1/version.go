@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "strings"
+
+// IsDowngrade reports whether spec.migrations.targetVersion is lower than the
+// version recorded in status.currentVersion. Without either value the
+// direction is unknown and the migration is treated as an upgrade.
+func (r *DBUpgrade) IsDowngrade() bool {
+	target := r.Spec.Migrations.TargetVersion
+	current := r.Status.CurrentVersion
+	if target == "" || current == "" {
+		return false
+	}
+	return CompareMigrationVersions(target, current) < 0
+}
+
+// CompareMigrationVersions compares two migration versions and returns -1, 0
+// or 1. Runs of digits are compared numerically and everything else as text,
+// so "20240102" > "20240101", "2.10" > "2.9" and "v1.2" < "v1.10".
+func CompareMigrationVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) && i < len(tb); i++ {
+		if c := compareVersionToken(ta[i], tb[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(ta) < len(tb):
+		return -1
+	case len(ta) > len(tb):
+		return 1
+	}
+	return 0
+}
+
+// versionTokens splits a version into alternating digit and non-digit runs
+func versionTokens(v string) []string {
+	var tokens []string
+	start := 0
+	for i := 1; i <= len(v); i++ {
+		if i == len(v) || isDigit(v[i]) != isDigit(v[i-1]) {
+			tokens = append(tokens, v[start:i])
+			start = i
+		}
+	}
+	return tokens
+}
+
+func compareVersionToken(a, b string) int {
+	if isDigit(a[0]) && isDigit(b[0]) {
+		// Compare without parsing so long timestamps never overflow
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command fetcher is the fetch-migrations init container of migration Jobs.
+// It fills the migrations volume from an image, an OCI artifact or a mounted
+// directory and exits with one of the fetcher.Exit* codes on failure.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+
+	"github.com/subganapathy/automatic-db-upgrades/internal/fetcher"
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	var image, artifact, sourceDir, dir, output, platform string
+	var insecure bool
+	flag.StringVar(&image, "image", "", "Image to extract the migrations directory from.")
+	flag.StringVar(&artifact, "artifact", "", "OCI artifact (oras push) to write the migration files from.")
+	flag.StringVar(&sourceDir, "source-dir", "", "Mounted ConfigMap or Secret to copy the migration files from.")
+	flag.StringVar(&dir, "dir", "/migrations", "Directory of the migration files.")
+	flag.StringVar(&output, "output", "/shared", "Migrations volume to write the files and manifest to.")
+	flag.StringVar(&platform, "platform", runtime.GOOS+"/"+runtime.GOARCH, "Platform to pick from multi-arch images.")
+	flag.BoolVar(&insecure, "insecure", false, "Allow plain HTTP and unverified TLS registries (local dev only).")
+	flag.Parse()
+
+	sources := 0
+	for _, s := range []string{image, artifact, sourceDir} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		fmt.Fprintln(os.Stderr, "exactly one of --image, --artifact and --source-dir is required")
+		return fetcher.ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	manifest, err := fetch(ctx, image, artifact, sourceDir, dir, output, platform, insecure)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetch-migrations: %v\n", err)
+		return fetcher.ExitCode(err)
+	}
+	fmt.Printf("Fetched %d migration files from %s\n", len(manifest.Files), manifest.Source)
+	return 0
+}
+
+func fetch(ctx context.Context, image, artifact, sourceDir, dir, output, platform string, insecure bool) (*fetcher.Manifest, error) {
+	// authn.DefaultKeychain reads $DOCKER_CONFIG/config.json, which the
+	// controller mounts for private registries
+	client := registry.NewClient(insecure)
+	source, digest := sourceDir, ""
+	switch {
+	case image != "":
+		p, err := registry.ParsePlatform(platform)
+		if err != nil {
+			return nil, err
+		}
+		img, err := client.Pull(ctx, image, p, authn.DefaultKeychain)
+		if err != nil {
+			return nil, err
+		}
+		if err := fetcher.ExtractImage(img, dir, output); err != nil {
+			return nil, err
+		}
+		source, digest = image, img.Digest
+	case artifact != "":
+		img, err := client.PullArtifact(ctx, artifact, authn.DefaultKeychain)
+		if err != nil {
+			return nil, err
+		}
+		if err := fetcher.ExtractArtifact(img, dir, output); err != nil {
+			return nil, err
+		}
+		source, digest = artifact, img.Digest
+	default:
+		if err := fetcher.CopyDir(sourceDir, dir, output); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := fetcher.BuildManifest(output, dir)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Source, manifest.Digest = source, digest
+	return manifest, fetcher.WriteManifest(output, manifest)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	awsutil "github.com/subganapathy/automatic-db-upgrades/internal/aws"
+	"github.com/subganapathy/automatic-db-upgrades/internal/dburl"
+)
+
+// mysqlTLSModes translates sslMode to go-sql-driver's tls parameter
+var mysqlTLSModes = map[string]string{
+	"disable":     "false",
+	"allow":       "preferred",
+	"prefer":      "preferred",
+	"require":     "skip-verify",
+	"verify-ca":   "true",
+	"verify-full": "true",
+}
+
+// structuredConnectionURL builds the canonical connection URL from the
+// structured connection fields and the password in passwordSecretRef
+func (r *DBUpgradeReconciler) structuredConnectionURL(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) ([]byte, error) {
+	conn := dbUpgrade.Spec.Database.Connection
+
+	passwordRef := conn.PasswordSecretRef
+	passwordSecret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: passwordRef.Name, Namespace: dbUpgrade.Namespace}, passwordSecret); err != nil {
+		return nil, fmt.Errorf("failed to get password secret: %w", err)
+	}
+	password, ok := passwordSecret.Data[passwordRef.Key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in secret %q", passwordRef.Key, passwordRef.Name)
+	}
+
+	return []byte(databaseURL(dbUpgrade, conn.Host, conn.Port, conn.Database, conn.Username, string(password), connectionParams(dbUpgrade))), nil
+}
+
+// connectionParams returns the query parameters of a structured connection:
+// sslMode in the engine's own form, overridden by explicit params
+func connectionParams(dbUpgrade *dbupgradev1alpha1.DBUpgrade) map[string]string {
+	conn := dbUpgrade.Spec.Database.Connection
+
+	params := map[string]string{}
+	if conn.SSLMode != "" {
+		if databaseEngine(dbUpgrade) == awsutil.EnginePostgres {
+			params["sslmode"] = conn.SSLMode
+		} else {
+			params["tls"] = mysqlTLSModes[conn.SSLMode]
+		}
+	}
+	for k, v := range conn.Params {
+		params[k] = v
+	}
+	return params
+}
+
+// databaseURL builds the canonical connection URL for credentials the
+// operator assembles itself (structured connection, Vault)
+func databaseURL(dbUpgrade *dbupgradev1alpha1.DBUpgrade, host string, port int32, dbName, username, password string, params map[string]string) string {
+	dbEngine := databaseEngine(dbUpgrade)
+	scheme := dbEngine
+	if dbEngine == awsutil.EngineMariaDB {
+		scheme = "maria"
+	}
+	if port == 0 {
+		port = awsutil.DefaultPort(dbEngine)
+	}
+
+	query := url.Values{}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	return dburl.URL{
+		Scheme:   scheme,
+		Username: username,
+		Password: password,
+		Host:     host,
+		Port:     port,
+		Database: dbName,
+		RawQuery: query.Encode(),
+	}.String()
+}
+
+// databaseEngine returns spec.database.engine, defaulting to postgres
+func databaseEngine(dbUpgrade *dbupgradev1alpha1.DBUpgrade) string {
+	if dbUpgrade.Spec.Database.Engine == "" {
+		return awsutil.EnginePostgres
+	}
+	return string(dbUpgrade.Spec.Database.Engine)
+}
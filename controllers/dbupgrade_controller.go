@@ -5,7 +5,11 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -22,6 +26,11 @@ import (
 	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
 	awsutil "github.com/subganapathy/automatic-db-upgrades/internal/aws"
 	"github.com/subganapathy/automatic-db-upgrades/internal/checks"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
+	"github.com/subganapathy/automatic-db-upgrades/internal/env"
+	"github.com/subganapathy/automatic-db-upgrades/internal/prometheus"
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+	"github.com/subganapathy/automatic-db-upgrades/internal/vault"
 )
 
 // DBUpgradeReconciler reconciles a DBUpgrade object
@@ -30,6 +39,16 @@ type DBUpgradeReconciler struct {
 	Scheme           *runtime.Scheme
 	RestConfig       *rest.Config
 	AWSClientManager *awsutil.ClientManager
+	VaultClient      *vault.Client
+	// RegistryClient pins migrations images by digest; nil disables pinning
+	RegistryClient *registry.Client
+	// PrometheusClient runs source=Prometheus metric checks
+	PrometheusClient *prometheus.Client
+	// HTTPClient runs HTTP checks; nil uses http.DefaultClient
+	HTTPClient *http.Client
+	// ImageVersionResolver reads image labels for versionSource=imageLabel
+	// pod version checks; nil fails those checks
+	ImageVersionResolver *checks.ImageVersionResolver
 }
 
 //+kubebuilder:rbac:groups=dbupgrade.subbug.learning,resources=dbupgrades,verbs=get;list;watch;create;update;patch;delete
@@ -43,6 +62,13 @@ type DBUpgradeReconciler struct {
 // RBAC for Secrets - controller creates RDS tokens or reads user-provided secrets
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
+// RBAC for ServiceAccount tokens - controller logs in to Vault as the tenant's ServiceAccount
+//+kubebuilder:rbac:groups="",resources=serviceaccounts/token,verbs=create
+
+// RBAC for Plan mode - controller reads runner output and stores it in a ConfigMap
+//+kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
 // RBAC for Events - controller emits events for observability
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
@@ -52,6 +78,9 @@ type DBUpgradeReconciler struct {
 // TODO: Future RBAC for pods access (pre-check: pod version validation)
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 
+// RBAC for workloads (pre-check: workload rollout status)
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets;replicasets,verbs=get;list;watch
+
 // TODO: Future RBAC for custom metrics (pre/post checks)
 //+kubebuilder:rbac:groups=custom.metrics.k8s.io,resources=*,verbs=get;list
 
@@ -60,22 +89,53 @@ type DBUpgradeReconciler struct {
 
 // Container images used for migration Jobs
 var (
-	// CraneImage extracts migrations from customer images
-	// Default uses :debug tag (includes shell/tar). Override via CRANE_IMAGE env var.
-	CraneImage = getEnvOrDefault("CRANE_IMAGE", "gcr.io/go-containerregistry/crane:debug")
+	// FetcherImage runs cmd/fetcher, which extracts migrations from customer
+	// images and copies ConfigMap and Secret sources. It is the operator image
+	// by default. Override via FETCHER_IMAGE env var.
+	// Runner images are owned by the engine package (see engine.AtlasImage).
+	FetcherImage = env.GetOrDefault("FETCHER_IMAGE", "ghcr.io/subganapathy/automatic-db-upgrades:latest")
 
-	// AtlasImage is the official Atlas CLI image for running migrations
-	AtlasImage = getEnvOrDefault("ATLAS_IMAGE", "arigaio/atlas:latest")
-
-	// AllowInsecureRegistries enables --insecure flag for crane (local dev only)
+	// AllowInsecureRegistries enables --insecure for the fetcher (local dev only)
 	AllowInsecureRegistries = os.Getenv("ALLOW_INSECURE_REGISTRIES") == "true"
 )
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// HTTPCheckAllowedHosts are the hosts HTTP checks may call by absolute URL,
+// as host names, IP addresses or *.domain wildcards. Set via the
+// comma-separated HTTP_CHECK_ALLOWED_HOSTS env var; when empty, HTTP checks
+// can only reference Services in the DBUpgrade's namespace.
+var HTTPCheckAllowedHosts = hostsFromEnv("HTTP_CHECK_ALLOWED_HOSTS")
+
+// PrometheusAllowedHosts are the Prometheus servers metric checks may query,
+// in the format of HTTPCheckAllowedHosts. Set via the comma-separated
+// PROMETHEUS_ALLOWED_HOSTS env var; when empty, source=Prometheus checks fail.
+var PrometheusAllowedHosts = hostsFromEnv("PROMETHEUS_ALLOWED_HOSTS")
+
+// TokenExpiresAtAnnotation records on the operator Secret when its IAM token
+// or Vault lease expires
+const TokenExpiresAtAnnotation = "dbupgrade.subbug.learning/token-expires-at"
+
+// tokenRefreshMargin is how long before expiry an IAM token is re-minted.
+// Reconciles of a pending or running Job requeue well within this margin.
+const tokenRefreshMargin = 5 * time.Minute
+
+// fetchContainerName is the init container that extracts the migrations image
+const fetchContainerName = "fetch-migrations"
+
+// DirectionAnnotation records on the Job whether it migrates up or down, or
+// only plans (spec.mode=Plan)
+const DirectionAnnotation = "dbupgrade.subbug.learning/direction"
+
+const (
+	directionUp   = "up"
+	directionDown = "down"
+	directionPlan = "plan"
+)
+
+// hostsFromEnv reads a comma-separated host allowlist (see checks.HostAllowed)
+func hostsFromEnv(key string) []string {
+	return strings.FieldsFunc(os.Getenv(key), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
 }
 
 // reconcileResult holds the state to be written to status at the end of reconcile
@@ -90,6 +150,19 @@ type reconcileResult struct {
 	event           *eventInfo
 	// jobCompletedAt is set when job succeeds, used for baketime tracking
 	jobCompletedAt *metav1.Time
+	// plan is set when a Plan-mode Job's output has been collected
+	plan *dbupgradev1alpha1.PlanStatus
+	// currentVersion, applied and lastAppliedImage are set once per
+	// successful Job from its runner output (see recordAppliedRevision)
+	currentVersion   *string
+	applied          *dbupgradev1alpha1.AppliedMigrationsStatus
+	lastAppliedImage string
+	// resolvedImage is the digest-pinned image of the current Job
+	resolvedImage string
+	// lastFailure is set when a Job failed
+	lastFailure *dbupgradev1alpha1.FailureStatus
+	// history is merged into status.history by JobName
+	history *dbupgradev1alpha1.MigrationHistoryEntry
 }
 
 type eventInfo struct {
@@ -112,6 +185,19 @@ func (r *DBUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
+	// Revoke Vault credentials before letting a deleted DBUpgrade go
+	if !dbUpgrade.DeletionTimestamp.IsZero() {
+		if err := r.finalizeVaultLease(ctx, dbUpgrade); err != nil {
+			logger.Error(err, "failed to finalize Vault lease")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if err := r.ensureVaultLeaseFinalizer(ctx, dbUpgrade); err != nil {
+		logger.Error(err, "failed to add finalizer")
+		return ctrl.Result{}, err
+	}
+
 	// 2. Run reconciliation logic and collect result
 	result := r.reconcileDBUpgrade(ctx, dbUpgrade)
 
@@ -170,21 +256,6 @@ func (r *DBUpgradeReconciler) reconcileDBUpgrade(ctx context.Context, dbUpgrade
 		}
 	}
 
-	// Ensure operator-managed Secret for the Job
-	migrationSecret, err := r.ensureMigrationSecret(ctx, dbUpgrade)
-	if err != nil {
-		logger.Error(err, "Failed to ensure migration secret")
-		return reconcileResult{
-			ready:           false,
-			readyReason:     dbupgradev1alpha1.ReasonSecretNotFound,
-			readyMessage:    "Failed to create migration secret",
-			progressing:     false,
-			progressReason:  dbupgradev1alpha1.ReasonSecretNotFound,
-			progressMessage: err.Error(),
-			requeueAfter:    10 * time.Second,
-		}
-	}
-
 	// Get current spec hash
 	currentHash := computeSpecHash(dbUpgrade.Spec)
 
@@ -227,6 +298,10 @@ func (r *DBUpgradeReconciler) reconcileDBUpgrade(ctx context.Context, dbUpgrade
 		// Job is not running (completed or failed) - safe to delete
 		logger.Info("Spec changed, deleting completed Job", "oldJob", existingJob.Name, "expectedJob", expectedJobName)
 
+		// Keep the old Job's final outcome in history; its image and start
+		// time were recorded when it was created
+		oldJobEntry := jobHistoryEntry(dbUpgrade, existingJob, "", "")
+
 		// Delete the old Job (propagation policy deletes pods too)
 		propagation := metav1.DeletePropagationBackground
 		if err := r.Delete(ctx, existingJob, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
@@ -239,6 +314,7 @@ func (r *DBUpgradeReconciler) reconcileDBUpgrade(ctx context.Context, dbUpgrade
 				progressReason:  dbupgradev1alpha1.ReasonInitializing,
 				progressMessage: "Deleting Job from previous spec",
 				requeueAfter:    5 * time.Second,
+				history:         oldJobEntry,
 			}
 		}
 
@@ -251,22 +327,74 @@ func (r *DBUpgradeReconciler) reconcileDBUpgrade(ctx context.Context, dbUpgrade
 			progressReason:  dbupgradev1alpha1.ReasonInitializing,
 			progressMessage: "Deleted old Job, will create new one",
 			requeueAfter:    2 * time.Second,
+			history:         oldJobEntry,
 			event:           &eventInfo{corev1.EventTypeNormal, "SpecChanged", "Spec changed, starting new migration"},
 		}
 	}
 
+	// Rollbacks must be opted into (also enforced by the webhook). Refuse
+	// before any credentials are issued for a Job that will not be created.
+	if existingJob == nil && dbUpgrade.IsDowngrade() && !dbUpgrade.Spec.Migrations.AllowDowngrade {
+		msg := fmt.Sprintf("targetVersion %s is lower than current version %s; set migrations.allowDowngrade=true to roll back",
+			dbUpgrade.Spec.Migrations.TargetVersion, dbUpgrade.Status.CurrentVersion)
+		return reconcileResult{
+			ready:           false,
+			readyReason:     dbupgradev1alpha1.ReasonDowngradeNotAllowed,
+			readyMessage:    msg,
+			progressing:     false,
+			progressReason:  dbupgradev1alpha1.ReasonDowngradeNotAllowed,
+			progressMessage: msg,
+			event:           &eventInfo{corev1.EventTypeWarning, "DowngradeNotAllowed", msg},
+		}
+	}
+
+	// Run prechecks before the Job is created (Plan mode never touches the
+	// schema). They come before any credentials are issued, so a DBUpgrade
+	// blocked by a failing check does not mint tokens or lease credentials on
+	// every requeue.
+	runChecks := existingJob == nil && dbUpgrade.Spec.Checks != nil && !isPlanMode(dbUpgrade)
+	if runChecks {
+		if preCheckResult := r.runPreChecks(ctx, dbUpgrade); !preCheckResult.ready {
+			return preCheckResult
+		}
+	}
+
+	// Ensure operator-managed Secret for the Job. This happens after stale
+	// Jobs are cleaned up so credentials are only issued for the current one.
+	migrationSecret, err := r.ensureMigrationSecret(ctx, dbUpgrade, expectedJobName, existingJob)
+	if err != nil {
+		logger.Error(err, "Failed to ensure migration secret")
+		return reconcileResult{
+			ready:           false,
+			readyReason:     dbupgradev1alpha1.ReasonSecretNotFound,
+			readyMessage:    "Failed to create migration secret",
+			progressing:     false,
+			progressReason:  dbupgradev1alpha1.ReasonSecretNotFound,
+			progressMessage: err.Error(),
+			requeueAfter:    10 * time.Second,
+		}
+	}
+
 	// Create Job if doesn't exist
 	if existingJob == nil {
-		// Run prechecks before creating the Job
-		if dbUpgrade.Spec.Checks != nil {
-			preCheckResult := r.runPreChecks(ctx, dbUpgrade)
+		// SQL prechecks connect with the credentials in the migration Secret.
+		// They run last: they start a Job, so cheaper checks fail first.
+		if runChecks && len(dbUpgrade.Spec.Checks.Pre.SQL) > 0 {
+			preCheckResult := r.runSQLChecks(ctx, dbUpgrade, expectedJobName, checkPhasePre, dbUpgrade.Spec.Checks.Pre.SQL, dbupgradev1alpha1.ReasonPreCheckSQLFailed)
 			if !preCheckResult.ready {
 				return preCheckResult
 			}
 		}
 
-		logger.Info("Creating migration Job", "jobName", expectedJobName)
-		job, err := r.createMigrationJob(ctx, dbUpgrade, migrationSecret, currentHash)
+		// Pin the image so a moved tag cannot change what the Job applies
+		image, err := r.resolveMigrationsImage(ctx, dbUpgrade, migrationSecret)
+		if err != nil {
+			logger.Error(err, "Failed to resolve migrations image")
+			return imageResolutionResult(err)
+		}
+
+		logger.Info("Creating migration Job", "jobName", expectedJobName, "image", image)
+		job, err := r.createMigrationJob(ctx, dbUpgrade, migrationSecret, currentHash, image)
 		if err != nil {
 			if errors.IsAlreadyExists(err) {
 				// Race condition - Job was just created, requeue
@@ -300,12 +428,27 @@ func (r *DBUpgradeReconciler) reconcileDBUpgrade(ctx context.Context, dbUpgrade
 			progressReason:  dbupgradev1alpha1.ReasonJobPending,
 			progressMessage: fmt.Sprintf("Created Job %s", job.Name),
 			requeueAfter:    5 * time.Second,
+			resolvedImage:   image,
+			history:         jobHistoryEntry(dbUpgrade, job, jobImage(dbUpgrade, job), dbupgradev1alpha1.ReasonJobPending),
 			event:           &eventInfo{corev1.EventTypeNormal, "MigrationStarted", fmt.Sprintf("Created migration Job %s", job.Name)},
 		}
 	}
 
 	// Sync Job status to conditions
-	return r.syncJobStatus(ctx, dbUpgrade, existingJob)
+	result := r.syncJobStatus(ctx, dbUpgrade, existingJob)
+	result.history = jobHistoryEntry(dbUpgrade, existingJob, jobImage(dbUpgrade, existingJob), result.progressReason)
+	result.resolvedImage = existingJob.Annotations[ResolvedImageAnnotation]
+
+	// Vault credentials are only needed while the Job runs
+	if dbUpgrade.Spec.Database.Vault != nil && (isJobSucceeded(existingJob) || isJobFailed(existingJob)) {
+		if err := r.revokeVaultLease(ctx, dbUpgrade, migrationSecret); err != nil {
+			logger.Error(err, "Failed to revoke Vault lease, will retry")
+			if result.requeueAfter == 0 {
+				result.requeueAfter = 30 * time.Second
+			}
+		}
+	}
+	return result
 }
 
 // updateStatus writes the reconcile result to the DBUpgrade status
@@ -318,6 +461,34 @@ func (r *DBUpgradeReconciler) updateStatus(ctx context.Context, dbUpgrade *dbupg
 		dbUpgrade.Status.JobCompletedAt = result.jobCompletedAt
 	}
 
+	// Update applied revision if a successful Job was recorded
+	if result.currentVersion != nil {
+		dbUpgrade.Status.CurrentVersion = *result.currentVersion
+	}
+	if result.applied != nil {
+		dbUpgrade.Status.AppliedMigrations = result.applied
+		dbUpgrade.Status.LastAppliedImage = result.lastAppliedImage
+	}
+
+	if result.resolvedImage != "" {
+		dbUpgrade.Status.ResolvedImage = result.resolvedImage
+	}
+
+	// Update failure details if a Job failed
+	if result.lastFailure != nil {
+		dbUpgrade.Status.LastFailure = result.lastFailure
+	}
+
+	// Record the Job in the bounded history
+	if result.history != nil {
+		dbUpgrade.Status.History = mergeHistory(dbUpgrade.Status.History, *result.history, historyLimit(dbUpgrade))
+	}
+
+	// Update plan summary if a Plan-mode Job was collected
+	if result.plan != nil {
+		dbUpgrade.Status.Plan = result.plan
+	}
+
 	// Set conditions
 	gen := dbUpgrade.Generation
 	dbupgradev1alpha1.SetReady(&dbUpgrade.Status.Conditions, result.ready, result.readyReason, result.readyMessage, gen)
@@ -329,15 +500,19 @@ func (r *DBUpgradeReconciler) updateStatus(ctx context.Context, dbUpgrade *dbupg
 
 // validateSecret validates that the connection Secret exists and has the required key
 func (r *DBUpgradeReconciler) validateSecret(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) error {
-	if dbUpgrade.Spec.Database.Type != dbupgradev1alpha1.DatabaseTypeSelfHosted {
+	if dbUpgrade.Spec.Database.Type != dbupgradev1alpha1.DatabaseTypeSelfHosted || dbUpgrade.Spec.Database.Vault != nil {
 		return nil
 	}
 
-	if dbUpgrade.Spec.Database.Connection == nil || dbUpgrade.Spec.Database.Connection.URLSecretRef == nil {
-		return fmt.Errorf("database.connection.urlSecretRef is required for selfHosted database")
+	conn := dbUpgrade.Spec.Database.Connection
+	if conn == nil || (conn.URLSecretRef == nil && conn.PasswordSecretRef == nil) {
+		return fmt.Errorf("database.connection.urlSecretRef or passwordSecretRef is required for selfHosted database")
 	}
 
-	secretRef := dbUpgrade.Spec.Database.Connection.URLSecretRef
+	secretRef := conn.URLSecretRef
+	if secretRef == nil {
+		secretRef = conn.PasswordSecretRef
+	}
 	secret := &corev1.Secret{}
 	if err := r.Get(ctx, types.NamespacedName{Name: secretRef.Name, Namespace: dbUpgrade.Namespace}, secret); err != nil {
 		if errors.IsNotFound(err) {
@@ -353,15 +528,46 @@ func (r *DBUpgradeReconciler) validateSecret(ctx context.Context, dbUpgrade *dbu
 	return nil
 }
 
-// ensureMigrationSecret creates or updates the operator-managed Secret for the migration Job
-func (r *DBUpgradeReconciler) ensureMigrationSecret(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) (*corev1.Secret, error) {
+// ensureMigrationSecret creates or updates the operator-managed Secret for the
+// migration Job jobName; job is that Job if it was already created
+func (r *DBUpgradeReconciler) ensureMigrationSecret(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, jobName string, job *batchv1.Job) (*corev1.Secret, error) {
 	logger := log.FromContext(ctx)
-	secretName := fmt.Sprintf("dbupgrade-%s-connection", dbUpgrade.Name)
+	secretName := migrationSecretName(dbUpgrade)
+
+	// Check if operator Secret already exists
+	existingSecret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: dbUpgrade.Namespace}, existingSecret)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to check migration secret: %w", err)
+	}
+	exists := err == nil
+	var current *corev1.Secret
+	if exists {
+		current = existingSecret
+	}
 
 	var connectionURL []byte
+	// annotations describe short-lived credentials (see credentialAnnotations)
+	annotations := map[string]string{}
 
 	switch dbUpgrade.Spec.Database.Type {
 	case dbupgradev1alpha1.DatabaseTypeSelfHosted:
+		if dbUpgrade.Spec.Database.Vault != nil {
+			connectionURL, annotations, err = r.ensureVaultCredentials(ctx, dbUpgrade, current, jobName, job)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		if dbUpgrade.Spec.Database.Connection.URLSecretRef == nil {
+			connectionURL, err = r.structuredConnectionURL(ctx, dbUpgrade)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+
 		// Read customer's Secret
 		customerSecretRef := dbUpgrade.Spec.Database.Connection.URLSecretRef
 		customerSecret := &corev1.Secret{}
@@ -371,58 +577,100 @@ func (r *DBUpgradeReconciler) ensureMigrationSecret(ctx context.Context, dbUpgra
 		connectionURL = customerSecret.Data[customerSecretRef.Key]
 
 	case dbupgradev1alpha1.DatabaseTypeAWSRDS, dbupgradev1alpha1.DatabaseTypeAWSAurora:
-		// Generate RDS IAM auth token using shared client manager
-		awsCfg := dbUpgrade.Spec.Database.AWS
-		if awsCfg == nil {
-			return nil, fmt.Errorf("database.aws configuration is required for AWS RDS/Aurora")
+		rdsAuthCfg, err := r.rdsAuthConfig(dbUpgrade)
+		if err != nil {
+			return nil, err
 		}
 
-		if r.AWSClientManager == nil {
-			return nil, fmt.Errorf("AWS client manager not configured - AWS support is disabled")
+		// Password from Secrets Manager instead of an IAM token. Read on every
+		// reconcile so rotated passwords reach the Secret.
+		if secretArn := dbUpgrade.Spec.Database.AWS.SecretsManagerSecretArn; secretArn != "" {
+			connectionURL, err = r.secretsManagerConnectionURL(ctx, rdsAuthCfg, secretArn)
+			if err != nil {
+				return nil, err
+			}
+			break
 		}
 
-		// ExternalID provides tenant isolation: "{namespace}/{name}"
-		// The target role's trust policy must require this exact ExternalID
-		// to prevent cross-tenant role assumption attacks.
-		externalID := fmt.Sprintf("%s/%s", dbUpgrade.Namespace, dbUpgrade.Name)
-
-		rdsAuthCfg := awsutil.RDSAuthConfig{
-			Region:     awsCfg.Region,
-			Host:       awsCfg.Host,
-			Port:       awsCfg.Port,
-			Username:   awsCfg.Username,
-			DBName:     awsCfg.DBName,
-			RoleArn:    awsCfg.RoleArn,
-			ExternalID: externalID,
+		// Reuse the current token until it is about to expire. Env vars are
+		// resolved when the migrate container starts, so keeping the Secret
+		// fresh while the Job is pending or fetching migrations is enough.
+		if exists {
+			if reusableToken(existingSecret, rdsAuthCfg, dbUpgrade.Spec.Database.TLS) {
+				connectionURL = existingSecret.Data[engine.URLKey]
+				annotations[TokenExpiresAtAnnotation] = existingSecret.Annotations[TokenExpiresAtAnnotation]
+				break
+			}
 		}
 
+		// Generate RDS IAM auth token using shared client manager
 		token, err := r.AWSClientManager.GenerateRDSAuthToken(ctx, rdsAuthCfg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate RDS auth token: %w", err)
 		}
+		expiresAt := time.Now().Add(awsutil.RDSAuthTokenTTL)
+		annotations[TokenExpiresAtAnnotation] = expiresAt.UTC().Format(time.RFC3339)
 
-		// Build connection URL with the token as password
-		// Atlas expects PostgreSQL URL format
-		url := awsutil.BuildPostgresConnectionURL(rdsAuthCfg, token)
-		connectionURL = []byte(url)
+		// Build connection URL with the token as password; engines derive
+		// their own formats from it (see migrationSecretData)
+		connectionURL = []byte(awsutil.BuildConnectionURL(rdsAuthCfg, token))
 		logger.Info("Generated RDS IAM auth token",
-			"host", awsCfg.Host,
-			"user", awsCfg.Username,
-			"externalID", externalID)
+			"engine", rdsAuthCfg.Engine,
+			"host", rdsAuthCfg.Host,
+			"user", rdsAuthCfg.Username,
+			"externalID", rdsAuthCfg.ExternalID,
+			"expiresAt", expiresAt.UTC().Format(time.RFC3339))
 
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", dbUpgrade.Spec.Database.Type)
 	}
 
-	// Check if operator Secret already exists
-	existingSecret := &corev1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: dbUpgrade.Namespace}, existingSecret)
-	if err == nil {
-		// Always update for AWS (token may have expired) or if URL changed for selfHosted
-		needsUpdate := dbUpgrade.Spec.Database.Type != dbupgradev1alpha1.DatabaseTypeSelfHosted ||
-			string(existingSecret.Data["url"]) != string(connectionURL)
-		if needsUpdate {
-			existingSecret.Data["url"] = connectionURL
+	// Point the driver at the TLS files mounted from this Secret
+	tlsURL, err := tlsConnectionURL(dbUpgrade.Spec.Database.TLS, string(connectionURL))
+	if err != nil {
+		return nil, err
+	}
+	connectionURL = []byte(tlsURL)
+
+	// Let the migration engine derive the keys it reads (JDBC URL, DSN, ...)
+	data, err := migrationSecretData(dbUpgrade, connectionURL)
+	if err != nil {
+		return nil, err
+	}
+	tlsData, err := r.databaseTLSData(ctx, dbUpgrade)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range tlsData {
+		data[k] = v
+	}
+
+	// Docker config for the fetcher to pull a private migrations image
+	dockerConfigJSON, registryAnnotations, err := r.registryAuthData(ctx, dbUpgrade, current)
+	if err != nil {
+		return nil, err
+	}
+	if dockerConfigJSON != nil {
+		data[dockerConfigKey] = dockerConfigJSON
+	}
+	for k, v := range registryAnnotations {
+		annotations[k] = v
+	}
+
+	if exists {
+		// Update if the URL (or token) changed or the engine needs different keys
+		if !reflect.DeepEqual(existingSecret.Data, data) || !credentialAnnotationsEqual(existingSecret.Annotations, annotations) {
+			existingSecret.Data = data
+			if existingSecret.Annotations == nil {
+				existingSecret.Annotations = map[string]string{}
+			}
+			for _, key := range credentialAnnotations {
+				if value, ok := annotations[key]; ok {
+					existingSecret.Annotations[key] = value
+				} else {
+					delete(existingSecret.Annotations, key)
+				}
+			}
 			if err := r.Update(ctx, existingSecret); err != nil {
 				return nil, fmt.Errorf("failed to update migration secret: %w", err)
 			}
@@ -430,15 +678,13 @@ func (r *DBUpgradeReconciler) ensureMigrationSecret(ctx context.Context, dbUpgra
 		}
 		return existingSecret, nil
 	}
-	if !errors.IsNotFound(err) {
-		return nil, fmt.Errorf("failed to check migration secret: %w", err)
-	}
 
 	// Create new operator-managed Secret
 	operatorSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: dbUpgrade.Namespace,
+			Name:        secretName,
+			Namespace:   dbUpgrade.Namespace,
+			Annotations: annotations,
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         "dbupgrade.subbug.learning/v1alpha1",
 				Kind:               "DBUpgrade",
@@ -449,7 +695,7 @@ func (r *DBUpgradeReconciler) ensureMigrationSecret(ctx context.Context, dbUpgra
 			}},
 		},
 		Type: corev1.SecretTypeOpaque,
-		Data: map[string][]byte{"url": connectionURL},
+		Data: data,
 	}
 
 	if err := r.Create(ctx, operatorSecret); err != nil {
@@ -459,7 +705,176 @@ func (r *DBUpgradeReconciler) ensureMigrationSecret(ctx context.Context, dbUpgra
 	return operatorSecret, nil
 }
 
-// getJobForDBUpgrade finds the Job owned by this DBUpgrade
+// rdsAuthConfig builds the IAM auth settings for an AWS RDS/Aurora database
+func (r *DBUpgradeReconciler) rdsAuthConfig(dbUpgrade *dbupgradev1alpha1.DBUpgrade) (awsutil.RDSAuthConfig, error) {
+	awsCfg := dbUpgrade.Spec.Database.AWS
+	if awsCfg == nil {
+		return awsutil.RDSAuthConfig{}, fmt.Errorf("database.aws configuration is required for AWS RDS/Aurora")
+	}
+
+	if r.AWSClientManager == nil {
+		return awsutil.RDSAuthConfig{}, fmt.Errorf("AWS client manager not configured - AWS support is disabled")
+	}
+
+	// ExternalID provides tenant isolation: "{namespace}/{name}"
+	// The target role's trust policy must require this exact ExternalID
+	// to prevent cross-tenant role assumption attacks.
+	externalID := fmt.Sprintf("%s/%s", dbUpgrade.Namespace, dbUpgrade.Name)
+
+	dbEngine := databaseEngine(dbUpgrade)
+	port := awsCfg.Port
+	if port == 0 {
+		port = awsutil.DefaultPort(dbEngine)
+	}
+
+	return awsutil.RDSAuthConfig{
+		Engine:     dbEngine,
+		Region:     awsCfg.Region,
+		Host:       awsCfg.Host,
+		Port:       port,
+		Username:   awsCfg.Username,
+		DBName:     awsCfg.DBName,
+		RoleArn:    awsCfg.RoleArn,
+		ExternalID: externalID,
+	}, nil
+}
+
+// credentialAnnotations are the operator-owned annotations describing the
+// short-lived credentials in the migration Secret
+var credentialAnnotations = []string{TokenExpiresAtAnnotation, VaultLeaseAnnotation, VaultLeaseJobAnnotation, RegistryTokenExpiresAtAnnotation}
+
+// credentialAnnotationsEqual compares the credential annotations of a Secret
+func credentialAnnotationsEqual(current, desired map[string]string) bool {
+	for _, key := range credentialAnnotations {
+		currentValue, currentOK := current[key]
+		desiredValue, desiredOK := desired[key]
+		if currentOK != desiredOK || currentValue != desiredValue {
+			return false
+		}
+	}
+	return true
+}
+
+// secretsManagerConnectionURL builds the connection URL from the password (and
+// username, if stored) in an AWS Secrets Manager secret
+func (r *DBUpgradeReconciler) secretsManagerConnectionURL(ctx context.Context, cfg awsutil.RDSAuthConfig, secretArn string) ([]byte, error) {
+	creds, err := r.AWSClientManager.GetDatabaseCredentials(ctx, awsutil.SecretsManagerConfig{
+		SecretArn:  secretArn,
+		RoleArn:    cfg.RoleArn,
+		ExternalID: cfg.ExternalID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database password from Secrets Manager: %w", err)
+	}
+
+	// Rotation with alternating users changes the username as well
+	if creds.Username != "" {
+		cfg.Username = creds.Username
+	}
+	return []byte(awsutil.BuildConnectionURL(cfg, creds.Password)), nil
+}
+
+// reusableToken reports whether the operator Secret holds an IAM token for
+// cfg (and the TLS settings) that is not yet due for refresh
+func reusableToken(secret *corev1.Secret, cfg awsutil.RDSAuthConfig, tls *dbupgradev1alpha1.DatabaseTLSSpec) bool {
+	expiresAt, ok := credentialsExpiry(secret)
+	if !ok || time.Until(expiresAt) <= tokenRefreshMargin {
+		return false
+	}
+
+	// The database settings may have changed since the token was minted;
+	// compare the URLs with their passwords (the tokens) redacted
+	current, err := url.Parse(string(secret.Data[engine.URLKey]))
+	if err != nil {
+		return false
+	}
+	expectedURL, err := tlsConnectionURL(tls, awsutil.BuildConnectionURL(cfg, "redacted"))
+	if err != nil {
+		return false
+	}
+	expected, err := url.Parse(expectedURL)
+	if err != nil {
+		return false
+	}
+	return current.Redacted() == expected.Redacted()
+}
+
+// credentialsExpiry reads when the credentials in the operator Secret expire
+func credentialsExpiry(secret *corev1.Secret) (time.Time, bool) {
+	raw, ok := secret.Annotations[TokenExpiresAtAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+// migrationSecretData builds the operator-managed Secret contents: the canonical
+// URL plus whatever engine-specific keys the configured engine needs
+func migrationSecretData(dbUpgrade *dbupgradev1alpha1.DBUpgrade, connectionURL []byte) (map[string][]byte, error) {
+	eng, err := engine.New(dbUpgrade.Spec.Migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	extra, err := eng.SecretData(string(connectionURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive %s connection settings: %w", eng.Name(), err)
+	}
+
+	data := map[string][]byte{engine.URLKey: connectionURL}
+	for k, v := range extra {
+		data[k] = v
+	}
+
+	if connectionFileMode(dbUpgrade) {
+		filer, ok := eng.(engine.ConnectionFiler)
+		if !ok {
+			return nil, fmt.Errorf("engine %s does not support runner.connectionMode=File", eng.Name())
+		}
+		files, err := filer.ConnectionFiles(string(connectionURL))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s connection files: %w", eng.Name(), err)
+		}
+		for k, v := range files {
+			data[k] = v
+		}
+	}
+
+	// SQL check Jobs read the connection from a file in the client's own format
+	if hasSQLChecks(dbUpgrade) {
+		files, err := engine.SQLCheckFiles(string(connectionURL))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render SQL check connection file: %w", err)
+		}
+		for k, v := range files {
+			data[k] = v
+		}
+	}
+	return data, nil
+}
+
+// hasSQLChecks reports whether any pre or post SQL checks are configured
+func hasSQLChecks(dbUpgrade *dbupgradev1alpha1.DBUpgrade) bool {
+	c := dbUpgrade.Spec.Checks
+	return c != nil && (len(c.Pre.SQL) > 0 || len(c.Post.SQL) > 0)
+}
+
+// connectionFileMode reports whether the runner reads the connection from a
+// config file instead of env vars
+func connectionFileMode(dbUpgrade *dbupgradev1alpha1.DBUpgrade) bool {
+	return dbUpgrade.Spec.Runner != nil && dbUpgrade.Spec.Runner.ConnectionMode == dbupgradev1alpha1.ConnectionModeFile
+}
+
+// migrationSecretName is the operator-managed Secret of a DBUpgrade
+func migrationSecretName(dbUpgrade *dbupgradev1alpha1.DBUpgrade) string {
+	return fmt.Sprintf("dbupgrade-%s-connection", dbUpgrade.Name)
+}
+
+// getJobForDBUpgrade finds the migration Job owned by this DBUpgrade
 func (r *DBUpgradeReconciler) getJobForDBUpgrade(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) (*batchv1.Job, error) {
 	jobList := &batchv1.JobList{}
 	if err := r.List(ctx, jobList, client.InNamespace(dbUpgrade.Namespace)); err != nil {
@@ -468,6 +883,9 @@ func (r *DBUpgradeReconciler) getJobForDBUpgrade(ctx context.Context, dbUpgrade
 
 	for i := range jobList.Items {
 		job := &jobList.Items[i]
+		if isCheckJob(job) {
+			continue
+		}
 		for _, owner := range job.OwnerReferences {
 			if owner.UID == dbUpgrade.UID {
 				return job, nil
@@ -477,8 +895,11 @@ func (r *DBUpgradeReconciler) getJobForDBUpgrade(ctx context.Context, dbUpgrade
 	return nil, nil
 }
 
-// computeSpecHash generates a hash of the spec for change detection
+// computeSpecHash generates a hash of the spec for change detection.
+// Fields that don't affect the migration (historyLimit) are excluded so
+// changing them doesn't start a new Job.
 func computeSpecHash(spec dbupgradev1alpha1.DBUpgradeSpec) string {
+	spec.HistoryLimit = nil
 	specJSON, err := json.Marshal(spec)
 	if err != nil {
 		return ""
@@ -488,37 +909,63 @@ func computeSpecHash(spec dbupgradev1alpha1.DBUpgradeSpec) string {
 }
 
 // createMigrationJob creates a Kubernetes Job to run database migrations
-func (r *DBUpgradeReconciler) createMigrationJob(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, migrationSecret *corev1.Secret, specHash string) (*batchv1.Job, error) {
+// with the migrations image pinned to image, if it was resolved
+func (r *DBUpgradeReconciler) createMigrationJob(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, migrationSecret *corev1.Secret, specHash, image string) (*batchv1.Job, error) {
 	logger := log.FromContext(ctx)
 	jobName := fmt.Sprintf("dbupgrade-%s-%s", dbUpgrade.Name, specHash)
 
+	eng, err := engine.New(dbUpgrade.Spec.Migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Runner container: apply, or the engine's dry-run/status command in Plan mode
+	runnerOpts := engine.Options{
+		Dir:            migrationsDirOrDefault(dbUpgrade),
+		SecretName:     migrationSecret.Name,
+		TargetVersion:  dbUpgrade.Spec.Migrations.TargetVersion,
+		ConnectionFile: connectionFileMode(dbUpgrade),
+	}
+	runner := eng.Container(runnerOpts)
+	direction := directionUp
+	switch {
+	case isPlanMode(dbUpgrade):
+		planner, ok := eng.(engine.Planner)
+		if !ok {
+			return nil, fmt.Errorf("engine %s does not support mode=Plan", eng.Name())
+		}
+		runner = planner.PlanContainer(runnerOpts)
+		direction = directionPlan
+	case dbUpgrade.IsDowngrade():
+		downgrader, ok := eng.(engine.Downgrader)
+		if !ok {
+			return nil, fmt.Errorf("engine %s does not support downgrades", eng.Name())
+		}
+		runner = downgrader.DownContainer(runnerOpts)
+		direction = directionDown
+	}
+
 	// Default timeout
 	activeDeadlineSeconds := int64(600)
 	if dbUpgrade.Spec.Runner != nil && dbUpgrade.Spec.Runner.ActiveDeadlineSeconds != nil {
 		activeDeadlineSeconds = *dbUpgrade.Spec.Runner.ActiveDeadlineSeconds
 	}
 
-	// Migrations directory
-	migrationsDir := "/migrations"
-	if dbUpgrade.Spec.Migrations.Dir != "" {
-		migrationsDir = dbUpgrade.Spec.Migrations.Dir
-	}
-
-	// Init container command
-	insecureFlag := ""
-	if AllowInsecureRegistries {
-		insecureFlag = "--insecure "
+	// Migrations volume and the init container that fills it
+	fetchRef := dbUpgrade.Spec.Migrations.ArtifactReference()
+	annotations := map[string]string{DirectionAnnotation: direction}
+	if image != "" {
+		fetchRef = image
+		annotations[ResolvedImageAnnotation] = image
 	}
-	initCommand := fmt.Sprintf(`crane export %s--platform linux/$(uname -m | sed 's/x86_64/amd64/' | sed 's/aarch64/arm64/') %s - | tar -xf - -C /shared %s`,
-		insecureFlag,
-		dbUpgrade.Spec.Migrations.Image,
-		migrationsDir[1:])
+	volumes, initContainers := migrationsFetch(dbUpgrade, fetchRef)
 
 	backoffLimit := int32(0)
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      jobName,
-			Namespace: dbUpgrade.Namespace,
+			Name:        jobName,
+			Namespace:   dbUpgrade.Namespace,
+			Annotations: annotations,
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         "dbupgrade.subbug.learning/v1alpha1",
 				Kind:               "DBUpgrade",
@@ -533,50 +980,47 @@ func (r *DBUpgradeReconciler) createMigrationJob(ctx context.Context, dbUpgrade
 			ActiveDeadlineSeconds: &activeDeadlineSeconds,
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
-					RestartPolicy: corev1.RestartPolicyNever,
-					Volumes: []corev1.Volume{{
-						Name: "migrations",
-						VolumeSource: corev1.VolumeSource{
-							EmptyDir: &corev1.EmptyDirVolumeSource{},
-						},
-					}},
-					InitContainers: []corev1.Container{{
-						Name:    "fetch-migrations",
-						Image:   CraneImage,
-						Command: []string{"sh", "-c"},
-						Args:    []string{initCommand},
-						VolumeMounts: []corev1.VolumeMount{{
-							Name:      "migrations",
-							MountPath: "/shared",
-						}},
-					}},
-					Containers: []corev1.Container{{
-						Name:    "migrate",
-						Image:   AtlasImage,
-						Command: []string{"/atlas", "migrate", "apply"},
-						Args: []string{
-							"--dir", fmt.Sprintf("file:///migrations%s", migrationsDir),
-							"--url", "$(DATABASE_URL)",
-						},
-						Env: []corev1.EnvVar{{
-							Name: "DATABASE_URL",
-							ValueFrom: &corev1.EnvVarSource{
-								SecretKeyRef: &corev1.SecretKeySelector{
-									LocalObjectReference: corev1.LocalObjectReference{Name: migrationSecret.Name},
-									Key:                  "url",
-								},
-							},
-						}},
-						VolumeMounts: []corev1.VolumeMount{{
-							Name:      "migrations",
-							MountPath: "/migrations",
-						}},
-					}},
+					RestartPolicy:  corev1.RestartPolicyNever,
+					Volumes:        volumes,
+					InitContainers: initContainers,
+					Containers:     []corev1.Container{runner},
 				},
 			},
 		},
 	}
 
+	applyRunnerOverrides(&job.Spec.Template, dbUpgrade.Spec.Runner)
+
+	// The runner reads its connection config file from the operator Secret
+	if runnerOpts.ConnectionFile {
+		readOnly := int32(0444)
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: engine.ConnectionVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: migrationSecret.Name, DefaultMode: &readOnly},
+			},
+		})
+	}
+
+	// TLS files are only read by the runner
+	if dbUpgrade.Spec.Database.TLS != nil {
+		volume, mount := databaseTLSVolumeFor(dbUpgrade, migrationSecret.Name)
+		podSpec := &job.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, mount)
+		ensureClientKeyReadable(podSpec, dbUpgrade)
+	}
+
+	// Registry credentials are only read by the fetch container
+	if _, ok := migrationSecret.Data[dockerConfigKey]; ok && len(job.Spec.Template.Spec.InitContainers) > 0 {
+		volume, mount, env := dockerConfigVolumeFor(migrationSecret.Name)
+		podSpec := &job.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+		fetch := &podSpec.InitContainers[0]
+		fetch.VolumeMounts = append(fetch.VolumeMounts, mount)
+		fetch.Env = append(fetch.Env, env)
+	}
+
 	if err := r.Create(ctx, job); err != nil {
 		return nil, err
 	}
@@ -600,6 +1044,11 @@ func (r *DBUpgradeReconciler) syncJobStatus(ctx context.Context, dbUpgrade *dbup
 		}
 	}
 
+	// Plan-mode Job succeeded - collect the plan instead of declaring the schema migrated
+	if isJobSucceeded(job) && isPlanMode(dbUpgrade) {
+		return r.syncPlanResult(ctx, dbUpgrade, job)
+	}
+
 	// Job succeeded
 	if isJobSucceeded(job) {
 		// Get job completion time from job status or status (for persistence across reconciles)
@@ -615,49 +1064,74 @@ func (r *DBUpgradeReconciler) syncJobStatus(ctx context.Context, dbUpgrade *dbup
 		}
 
 		// Run postchecks before declaring success
-		if dbUpgrade.Spec.Checks != nil && len(dbUpgrade.Spec.Checks.Post.Metrics) > 0 {
-			postCheckResult := r.runPostChecks(ctx, dbUpgrade, jobCompletedAt)
+		if c := dbUpgrade.Spec.Checks; c != nil && (len(c.Post.Metrics) > 0 || len(c.Post.HTTP) > 0 || len(c.Post.SQL) > 0) {
+			postCheckResult := r.runPostChecks(ctx, dbUpgrade, job.Name, jobCompletedAt)
 			if !postCheckResult.ready {
 				// Preserve jobCompletedAt in result so it gets persisted
 				postCheckResult.jobCompletedAt = jobCompletedAt
+				// The schema changed regardless of the postcheck outcome
+				r.recordAppliedRevision(ctx, dbUpgrade, job, &postCheckResult)
 				return postCheckResult
 			}
 		}
 
+		target := dbUpgrade.Spec.Migrations.TargetVersion
+		readyMessage := "Database migration completed successfully"
+		eventReason := "MigrationSucceeded"
+		if target != "" {
+			readyMessage = fmt.Sprintf("Database migrated to target version %s", target)
+		}
+		if isDowngradeJob(job) {
+			readyMessage = fmt.Sprintf("Database rolled back to version %s", target)
+			eventReason = "DowngradeSucceeded"
+		}
+
 		logger.Info("Migration completed successfully", "job", job.Name)
-		return reconcileResult{
+		result := reconcileResult{
 			ready:           true,
 			readyReason:     dbupgradev1alpha1.ReasonMigrationComplete,
-			readyMessage:    "Database migration completed successfully",
+			readyMessage:    readyMessage,
 			progressing:     false,
 			progressReason:  dbupgradev1alpha1.ReasonMigrationComplete,
 			progressMessage: fmt.Sprintf("Job %s completed", job.Name),
 			jobCompletedAt:  jobCompletedAt,
-			event:           &eventInfo{corev1.EventTypeNormal, "MigrationSucceeded", "Database migration completed successfully"},
+			event:           &eventInfo{corev1.EventTypeNormal, eventReason, readyMessage},
 		}
+		r.recordAppliedRevision(ctx, dbUpgrade, job, &result)
+		return result
 	}
 
 	// Job failed
 	if isJobFailed(job) {
+		failure := r.describeFailure(ctx, dbUpgrade, job)
+		eventMessage := fmt.Sprintf("Database migration failed (%s)", failure.Type)
+		if failure.Excerpt != "" {
+			eventMessage = fmt.Sprintf("%s: %s", eventMessage, failure.Excerpt)
+		}
 		return reconcileResult{
 			ready:           false,
 			readyReason:     dbupgradev1alpha1.ReasonJobFailed,
-			readyMessage:    "Migration Job failed",
+			readyMessage:    fmt.Sprintf("Migration Job failed (%s); see status.lastFailure", failure.Type),
 			progressing:     false,
 			progressReason:  dbupgradev1alpha1.ReasonJobFailed,
 			progressMessage: fmt.Sprintf("Job %s failed", job.Name),
-			event:           &eventInfo{corev1.EventTypeWarning, "MigrationFailed", "Database migration failed"},
+			event:           &eventInfo{corev1.EventTypeWarning, "MigrationFailed", eventMessage},
+			lastFailure:     failure,
 		}
 	}
 
 	// Job running
 	if isJobRunning(job) {
+		progressReason := dbupgradev1alpha1.ReasonMigrationInProgress
+		if isDowngradeJob(job) {
+			progressReason = dbupgradev1alpha1.ReasonDowngradeInProgress
+		}
 		return reconcileResult{
 			ready:           false,
 			readyReason:     dbupgradev1alpha1.ReasonInitializing,
 			readyMessage:    "Migration in progress",
 			progressing:     true,
-			progressReason:  dbupgradev1alpha1.ReasonMigrationInProgress,
+			progressReason:  progressReason,
 			progressMessage: fmt.Sprintf("Job %s is running", job.Name),
 			requeueAfter:    10 * time.Second,
 		}
@@ -675,6 +1149,189 @@ func (r *DBUpgradeReconciler) syncJobStatus(ctx context.Context, dbUpgrade *dbup
 	}
 }
 
+// recordAppliedRevision fills the applied revision of a succeeded Job into
+// result, parsing the runner output once per Job. If the output can't be read
+// (e.g. the pod was garbage collected) the version falls back to the target.
+func (r *DBUpgradeReconciler) recordAppliedRevision(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, job *batchv1.Job, result *reconcileResult) {
+	logger := log.FromContext(ctx)
+
+	// Already recorded for this Job
+	if applied := dbUpgrade.Status.AppliedMigrations; applied != nil && applied.JobName == job.Name {
+		return
+	}
+
+	var rev engine.Revision
+	// Down Jobs land exactly on the target; their output is not apply output
+	if !isDowngradeJob(job) {
+		eng, err := engine.New(dbUpgrade.Spec.Migrations)
+		if err == nil {
+			var output string
+			output, err = r.getContainerLogs(ctx, job, engine.ContainerName, 0)
+			if err == nil {
+				rev = eng.ParseRevision(output)
+			}
+		}
+		if err != nil {
+			logger.Info("Could not read migration output, applied revision is unknown", "job", job.Name, "error", err.Error())
+		}
+	}
+
+	version := appliedVersion(rev, dbUpgrade.Spec.Migrations.TargetVersion, dbUpgrade.Status.CurrentVersion)
+	result.currentVersion = &version
+	result.applied = &dbupgradev1alpha1.AppliedMigrationsStatus{
+		JobName:  job.Name,
+		Count:    int32(rev.Applied),
+		LastFile: rev.LastFile,
+	}
+	result.lastAppliedImage = jobImage(dbUpgrade, job)
+}
+
+// appliedVersion picks the version the database is at after a successful Job:
+// what the engine reported, else the target it was told to stop at, else the
+// previous version if the run applied nothing.
+func appliedVersion(rev engine.Revision, target, previous string) string {
+	switch {
+	case rev.Version != "":
+		return rev.Version
+	case target != "":
+		return target
+	case rev.Applied == 0:
+		return previous
+	}
+	return ""
+}
+
+// syncPlanResult stores the output of a succeeded Plan-mode Job in an
+// operator-owned ConfigMap and summarizes it in status.plan
+func (r *DBUpgradeReconciler) syncPlanResult(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, job *batchv1.Job) reconcileResult {
+	logger := log.FromContext(ctx)
+
+	result := reconcileResult{
+		ready:           false,
+		readyReason:     dbupgradev1alpha1.ReasonPlanComplete,
+		readyMessage:    "Plan generated; set spec.mode=Apply to run it",
+		progressing:     false,
+		progressReason:  dbupgradev1alpha1.ReasonPlanComplete,
+		progressMessage: fmt.Sprintf("Job %s completed", job.Name),
+	}
+
+	// Output already collected for this Job
+	if dbUpgrade.Status.Plan != nil && dbUpgrade.Status.Plan.JobName == job.Name {
+		result.readyMessage = planMessage(dbUpgrade.Status.Plan)
+		return result
+	}
+
+	eng, err := engine.New(dbUpgrade.Spec.Migrations)
+	if err != nil {
+		result.readyMessage = err.Error()
+		return result
+	}
+	planner, ok := eng.(engine.Planner)
+	if !ok {
+		result.readyMessage = fmt.Sprintf("engine %s does not support mode=Plan", eng.Name())
+		return result
+	}
+
+	output, err := r.getContainerLogs(ctx, job, engine.ContainerName, 0)
+	if err != nil {
+		logger.Error(err, "Failed to read plan output", "job", job.Name)
+		result.readyMessage = fmt.Sprintf("Plan Job completed but output could not be read: %v", err)
+		result.requeueAfter = 30 * time.Second
+		return result
+	}
+
+	configMapName, err := r.ensurePlanConfigMap(ctx, dbUpgrade, job, output)
+	if err != nil {
+		logger.Error(err, "Failed to store plan output", "job", job.Name)
+		result.readyMessage = fmt.Sprintf("Plan Job completed but output could not be stored: %v", err)
+		result.requeueAfter = 30 * time.Second
+		return result
+	}
+
+	now := metav1.Now()
+	result.plan = &dbupgradev1alpha1.PlanStatus{
+		JobName:           job.Name,
+		Image:             jobImage(dbUpgrade, job),
+		ConfigMapName:     configMapName,
+		PendingMigrations: planner.PendingMigrations(output),
+		GeneratedAt:       &now,
+	}
+	result.readyMessage = planMessage(result.plan)
+	result.event = &eventInfo{corev1.EventTypeNormal, "PlanGenerated", result.readyMessage}
+	logger.Info("Migration plan generated", "job", job.Name, "pending", len(result.plan.PendingMigrations))
+	return result
+}
+
+// ensurePlanConfigMap creates or updates the operator-owned ConfigMap holding plan output
+func (r *DBUpgradeReconciler) ensurePlanConfigMap(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, job *batchv1.Job, output string) (string, error) {
+	name := fmt.Sprintf("dbupgrade-%s-plan", dbUpgrade.Name)
+	data := map[string]string{
+		"plan":  output,
+		"image": jobImage(dbUpgrade, job),
+		"job":   job.Name,
+	}
+
+	existing := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: dbUpgrade.Namespace}, existing)
+	if err == nil {
+		existing.Data = data
+		if err := r.Update(ctx, existing); err != nil {
+			return "", fmt.Errorf("failed to update plan ConfigMap: %w", err)
+		}
+		return name, nil
+	}
+	if !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to check plan ConfigMap: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: dbUpgrade.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "dbupgrade.subbug.learning/v1alpha1",
+				Kind:               "DBUpgrade",
+				Name:               dbUpgrade.Name,
+				UID:                dbUpgrade.UID,
+				Controller:         boolPtr(true),
+				BlockOwnerDeletion: boolPtr(true),
+			}},
+		},
+		Data: data,
+	}
+	if err := r.Create(ctx, configMap); err != nil {
+		return "", fmt.Errorf("failed to create plan ConfigMap: %w", err)
+	}
+	return name, nil
+}
+
+func planMessage(plan *dbupgradev1alpha1.PlanStatus) string {
+	return fmt.Sprintf("Plan generated: %d pending migration(s), see ConfigMap %s; set spec.mode=Apply to run them",
+		len(plan.PendingMigrations), plan.ConfigMapName)
+}
+
+func isPlanMode(dbUpgrade *dbupgradev1alpha1.DBUpgrade) bool {
+	return dbUpgrade.Spec.Mode == dbupgradev1alpha1.MigrationModePlan
+}
+
+// migrationsDirOrDefault returns spec.migrations.dir, defaulting to /migrations
+func migrationsDirOrDefault(dbUpgrade *dbupgradev1alpha1.DBUpgrade) string {
+	if dbUpgrade.Spec.Migrations.Dir != "" {
+		return dbUpgrade.Spec.Migrations.Dir
+	}
+	return "/migrations"
+}
+
+// isDowngradeJob reports whether the Job runs the engine's down path
+func isDowngradeJob(job *batchv1.Job) bool {
+	return job != nil && job.Annotations[DirectionAnnotation] == directionDown
+}
+
+// isPlanJob reports whether the Job only reports pending migrations
+func isPlanJob(job *batchv1.Job) bool {
+	return job != nil && job.Annotations[DirectionAnnotation] == directionPlan
+}
+
 func isJobRunning(job *batchv1.Job) bool {
 	if job == nil {
 		return false
@@ -740,7 +1397,8 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-// runPreChecks runs all prechecks and returns a reconcileResult
+// runPreChecks runs the prechecks that need no database credentials and
+// returns a reconcileResult. SQL prechecks run once the migration Secret exists.
 func (r *DBUpgradeReconciler) runPreChecks(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) reconcileResult {
 	logger := log.FromContext(ctx)
 
@@ -750,7 +1408,7 @@ func (r *DBUpgradeReconciler) runPreChecks(ctx context.Context, dbUpgrade *dbupg
 
 	// Run pod version checks
 	if len(dbUpgrade.Spec.Checks.Pre.MinPodVersions) > 0 {
-		result, err := checks.CheckMinPodVersions(ctx, r.Client, dbUpgrade.Namespace, dbUpgrade.Spec.Checks.Pre.MinPodVersions)
+		result, err := checks.CheckMinPodVersions(ctx, r.Client, r.ImageVersionResolver, dbUpgrade.Namespace, dbUpgrade.Spec.Checks.Pre.MinPodVersions)
 		if err != nil {
 			logger.Error(err, "Failed to run pod version check")
 			return reconcileResult{
@@ -779,12 +1437,43 @@ func (r *DBUpgradeReconciler) runPreChecks(ctx context.Context, dbUpgrade *dbupg
 		logger.Info("Pod version precheck passed", "message", result.Message)
 	}
 
+	// Run workload rollout checks
+	if len(dbUpgrade.Spec.Checks.Pre.WorkloadRollout) > 0 {
+		result, err := checks.CheckWorkloadRollouts(ctx, r.Client, dbUpgrade.Namespace, dbUpgrade.Spec.Checks.Pre.WorkloadRollout)
+		if err != nil {
+			logger.Error(err, "Failed to run workload rollout check")
+			return reconcileResult{
+				ready:           false,
+				readyReason:     dbupgradev1alpha1.ReasonPreCheckWorkloadRolloutFailed,
+				readyMessage:    err.Error(),
+				progressing:     false,
+				progressReason:  dbupgradev1alpha1.ReasonPreCheckWorkloadRolloutFailed,
+				progressMessage: "Error running workload rollout check",
+				requeueAfter:    30 * time.Second,
+			}
+		}
+		if !result.Passed {
+			logger.Info("Workload rollout precheck failed", "message", result.Message, "pending", result.Pending)
+			return reconcileResult{
+				ready:           false,
+				readyReason:     dbupgradev1alpha1.ReasonPreCheckWorkloadRolloutFailed,
+				readyMessage:    result.Message,
+				progressing:     false,
+				progressReason:  dbupgradev1alpha1.ReasonPreCheckWorkloadRolloutFailed,
+				progressMessage: result.Message,
+				requeueAfter:    30 * time.Second,
+				event:           &eventInfo{corev1.EventTypeWarning, "PreCheckFailed", result.Message},
+			}
+		}
+		logger.Info("Workload rollout precheck passed", "message", result.Message)
+	}
+
 	// Run metric checks
 	if len(dbUpgrade.Spec.Checks.Pre.Metrics) > 0 {
 		if r.RestConfig == nil {
 			logger.Info("RestConfig not available for metric checks, skipping")
 		} else {
-			metricsChecker, err := checks.NewMetricsChecker(r.RestConfig)
+			metricsChecker, err := checks.NewMetricsChecker(r.RestConfig, r.Client, r.PrometheusClient, PrometheusAllowedHosts)
 			if err != nil {
 				logger.Error(err, "Failed to create metrics checker")
 				return reconcileResult{
@@ -828,26 +1517,41 @@ func (r *DBUpgradeReconciler) runPreChecks(ctx context.Context, dbUpgrade *dbupg
 		}
 	}
 
+	if len(dbUpgrade.Spec.Checks.Pre.HTTP) > 0 {
+		if result := r.runHTTPChecks(ctx, dbUpgrade, dbUpgrade.Spec.Checks.Pre.HTTP, dbupgradev1alpha1.ReasonPreCheckHTTPFailed, "PreCheckFailed"); !result.ready {
+			return result
+		}
+	}
+
 	return reconcileResult{ready: true}
 }
 
 // runPostChecks runs all postchecks and returns a reconcileResult
 // jobCompletedAt is used for baketime calculation instead of blocking sleep
-func (r *DBUpgradeReconciler) runPostChecks(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, jobCompletedAt *metav1.Time) reconcileResult {
+func (r *DBUpgradeReconciler) runPostChecks(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, jobName string, jobCompletedAt *metav1.Time) reconcileResult {
 	logger := log.FromContext(ctx)
 
-	if dbUpgrade.Spec.Checks == nil || len(dbUpgrade.Spec.Checks.Post.Metrics) == 0 {
+	if dbUpgrade.Spec.Checks == nil {
 		return reconcileResult{ready: true}
 	}
-
-	if r.RestConfig == nil {
-		logger.Info("RestConfig not available for metric checks, skipping")
+	post := dbUpgrade.Spec.Checks.Post
+	if len(post.Metrics) == 0 && len(post.HTTP) == 0 && len(post.SQL) == 0 {
 		return reconcileResult{ready: true}
 	}
 
 	// Calculate max baketime from all postchecks
 	var maxBakeSeconds int32
-	for _, check := range dbUpgrade.Spec.Checks.Post.Metrics {
+	for _, check := range post.Metrics {
+		if check.BakeSeconds > maxBakeSeconds {
+			maxBakeSeconds = check.BakeSeconds
+		}
+	}
+	for _, check := range post.HTTP {
+		if check.BakeSeconds > maxBakeSeconds {
+			maxBakeSeconds = check.BakeSeconds
+		}
+	}
+	for _, check := range post.SQL {
 		if check.BakeSeconds > maxBakeSeconds {
 			maxBakeSeconds = check.BakeSeconds
 		}
@@ -877,49 +1581,106 @@ func (r *DBUpgradeReconciler) runPostChecks(ctx context.Context, dbUpgrade *dbup
 			"required", maxBakeSeconds)
 	}
 
-	metricsChecker, err := checks.NewMetricsChecker(r.RestConfig)
-	if err != nil {
-		logger.Error(err, "Failed to create metrics checker")
-		return reconcileResult{
-			ready:           false,
-			readyReason:     dbupgradev1alpha1.ReasonPostCheckFailed,
-			readyMessage:    "Failed to create metrics checker",
-			progressing:     false,
-			progressReason:  dbupgradev1alpha1.ReasonPostCheckFailed,
-			progressMessage: err.Error(),
-			requeueAfter:    30 * time.Second,
+	// Run metric checks
+	if len(post.Metrics) > 0 {
+		if r.RestConfig == nil {
+			logger.Info("RestConfig not available for metric checks, skipping")
+		} else {
+			metricsChecker, err := checks.NewMetricsChecker(r.RestConfig, r.Client, r.PrometheusClient, PrometheusAllowedHosts)
+			if err != nil {
+				logger.Error(err, "Failed to create metrics checker")
+				return reconcileResult{
+					ready:           false,
+					readyReason:     dbupgradev1alpha1.ReasonPostCheckFailed,
+					readyMessage:    "Failed to create metrics checker",
+					progressing:     false,
+					progressReason:  dbupgradev1alpha1.ReasonPostCheckFailed,
+					progressMessage: err.Error(),
+					requeueAfter:    30 * time.Second,
+				}
+			}
+
+			// Pass nil for completedAt since we've already handled baketime at controller level
+			result, err := metricsChecker.CheckMetrics(ctx, dbUpgrade.Namespace, post.Metrics)
+			if err != nil {
+				logger.Error(err, "Failed to run metric postcheck")
+				return reconcileResult{
+					ready:           false,
+					readyReason:     dbupgradev1alpha1.ReasonPostCheckFailed,
+					readyMessage:    err.Error(),
+					progressing:     false,
+					progressReason:  dbupgradev1alpha1.ReasonPostCheckFailed,
+					progressMessage: "Error running metric check",
+					requeueAfter:    30 * time.Second,
+				}
+			}
+			if !result.Passed {
+				logger.Info("Metric postcheck failed", "message", result.Message)
+				return reconcileResult{
+					ready:           false,
+					readyReason:     dbupgradev1alpha1.ReasonPostCheckFailed,
+					readyMessage:    result.Message,
+					progressing:     false,
+					progressReason:  dbupgradev1alpha1.ReasonPostCheckFailed,
+					progressMessage: result.Message,
+					requeueAfter:    60 * time.Second,
+					event:           &eventInfo{corev1.EventTypeWarning, "PostCheckFailed", result.Message},
+				}
+			}
+			logger.Info("Metric postcheck passed", "message", result.Message)
 		}
 	}
 
-	// Pass nil for completedAt since we've already handled baketime at controller level
-	result, err := metricsChecker.CheckMetrics(ctx, dbUpgrade.Namespace, dbUpgrade.Spec.Checks.Post.Metrics)
+	if len(post.HTTP) > 0 {
+		if result := r.runHTTPChecks(ctx, dbUpgrade, post.HTTP, dbupgradev1alpha1.ReasonPostCheckFailed, "PostCheckFailed"); !result.ready {
+			return result
+		}
+	}
+
+	if len(post.SQL) > 0 {
+		return r.runSQLChecks(ctx, dbUpgrade, jobName, checkPhasePost, post.SQL, dbupgradev1alpha1.ReasonPostCheckSQLFailed)
+	}
+
+	return reconcileResult{ready: true}
+}
+
+// runHTTPChecks runs pre or post HTTP checks. A failed check sets failReason
+// and emits a warning event with eventReason.
+func (r *DBUpgradeReconciler) runHTTPChecks(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, httpChecks []dbupgradev1alpha1.HTTPCheck, failReason, eventReason string) reconcileResult {
+	logger := log.FromContext(ctx)
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	result, err := checks.CheckHTTP(ctx, r.Client, httpClient, HTTPCheckAllowedHosts, dbUpgrade.Namespace, httpChecks)
 	if err != nil {
-		logger.Error(err, "Failed to run metric postcheck")
+		logger.Error(err, "Failed to run HTTP check")
 		return reconcileResult{
 			ready:           false,
-			readyReason:     dbupgradev1alpha1.ReasonPostCheckFailed,
+			readyReason:     failReason,
 			readyMessage:    err.Error(),
 			progressing:     false,
-			progressReason:  dbupgradev1alpha1.ReasonPostCheckFailed,
-			progressMessage: "Error running metric check",
+			progressReason:  failReason,
+			progressMessage: "Error running HTTP check",
 			requeueAfter:    30 * time.Second,
 		}
 	}
 	if !result.Passed {
-		logger.Info("Metric postcheck failed", "message", result.Message)
+		logger.Info("HTTP check failed", "message", result.Message)
 		return reconcileResult{
 			ready:           false,
-			readyReason:     dbupgradev1alpha1.ReasonPostCheckFailed,
+			readyReason:     failReason,
 			readyMessage:    result.Message,
 			progressing:     false,
-			progressReason:  dbupgradev1alpha1.ReasonPostCheckFailed,
+			progressReason:  failReason,
 			progressMessage: result.Message,
 			requeueAfter:    60 * time.Second,
-			event:           &eventInfo{corev1.EventTypeWarning, "PostCheckFailed", result.Message},
+			event:           &eventInfo{corev1.EventTypeWarning, eventReason, result.Message},
 		}
 	}
-	logger.Info("Metric postcheck passed", "message", result.Message)
-
+	logger.Info("HTTP check passed", "message", result.Message)
 	return reconcileResult{ready: true}
 }
 
@@ -24,11 +24,13 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
 )
 
 var _ = Describe("DBUpgrade Controller", func() {
@@ -209,17 +211,17 @@ var _ = Describe("DBUpgrade Controller", func() {
 			Expect(createdJob.Spec.BackoffLimit).NotTo(BeNil())
 			Expect(*createdJob.Spec.BackoffLimit).To(Equal(int32(0)))
 
-			// Verify init container (crane for extracting migrations)
+			// Verify init container (fetcher for extracting migrations)
 			Expect(createdJob.Spec.Template.Spec.InitContainers).To(HaveLen(1))
 			initContainer := createdJob.Spec.Template.Spec.InitContainers[0]
 			Expect(initContainer.Name).To(Equal("fetch-migrations"))
-			Expect(initContainer.Image).To(Equal(CraneImage))
+			Expect(initContainer.Image).To(Equal(FetcherImage))
 
 			// Verify main container (Atlas CLI)
 			Expect(createdJob.Spec.Template.Spec.Containers).To(HaveLen(1))
 			mainContainer := createdJob.Spec.Template.Spec.Containers[0]
 			Expect(mainContainer.Name).To(Equal("migrate"))
-			Expect(mainContainer.Image).To(Equal(AtlasImage))
+			Expect(mainContainer.Image).To(Equal(engine.AtlasImage))
 			Expect(mainContainer.Env).To(HaveLen(1))
 			Expect(mainContainer.Env[0].Name).To(Equal("DATABASE_URL"))
 			// Should use operator-managed secret, not customer's secret directly
@@ -565,4 +567,48 @@ var _ = Describe("DBUpgrade Controller", func() {
 			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
 		})
 	})
+
+	Context("Vault Lease Finalizer", func() {
+		It("should hold deletion until the Vault lease is finalized", func() {
+			dbUpgrade := &dbupgradev1alpha1.DBUpgrade{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-vault-finalizer",
+					Namespace: "default",
+				},
+				Spec: dbupgradev1alpha1.DBUpgradeSpec{
+					Migrations: dbupgradev1alpha1.MigrationsSpec{
+						Image: "test:v1",
+					},
+					Database: dbupgradev1alpha1.DatabaseSpec{
+						Type: dbupgradev1alpha1.DatabaseTypeSelfHosted,
+						Vault: &dbupgradev1alpha1.VaultSpec{
+							Address:  "https://vault.vault.svc:8200",
+							AuthRole: "migrator",
+							Role:     "app-migrations",
+							Host:     "postgres.db.svc",
+							DBName:   "app",
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, dbUpgrade)).To(Succeed())
+
+			// The finalizer is added before any lease is requested
+			Eventually(func() []string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dbUpgrade), dbUpgrade); err != nil {
+					return nil
+				}
+				return dbUpgrade.Finalizers
+			}, timeout, interval).Should(ContainElement(VaultLeaseFinalizer))
+
+			// No lease was issued (Vault is not configured in the suite), so
+			// the finalizer is removed and the DBUpgrade goes away
+			Expect(k8sClient.Delete(ctx, dbUpgrade)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dbUpgrade), dbUpgrade)
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
 })
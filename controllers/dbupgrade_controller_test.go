@@ -2,11 +2,15 @@ package controllers
 
 import (
 	"testing"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	awsutil "github.com/subganapathy/automatic-db-upgrades/internal/aws"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
 )
 
 // TestComputeSpecHash tests the spec hash computation
@@ -230,3 +234,153 @@ func TestIsJobFailed(t *testing.T) {
 		})
 	}
 }
+
+// TestIsDowngradeJob tests the isDowngradeJob helper
+func TestIsDowngradeJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      *batchv1.Job
+		expected bool
+	}{
+		{
+			name:     "nil job",
+			job:      nil,
+			expected: false,
+		},
+		{
+			name: "down job",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{DirectionAnnotation: directionDown},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "up job",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{DirectionAnnotation: directionUp},
+				},
+			},
+			expected: false,
+		},
+		{
+			name:     "job created before direction tracking",
+			job:      &batchv1.Job{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isDowngradeJob(tt.job)
+			if result != tt.expected {
+				t.Errorf("isDowngradeJob() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestAppliedVersion tests the fallback order for status.currentVersion
+func TestAppliedVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		rev      engine.Revision
+		target   string
+		previous string
+		expected string
+	}{
+		{
+			name:     "engine reported version wins",
+			rev:      engine.Revision{Version: "5", Applied: 2},
+			target:   "4",
+			previous: "3",
+			expected: "5",
+		},
+		{
+			name:     "falls back to target",
+			rev:      engine.Revision{Applied: 2},
+			target:   "4",
+			previous: "3",
+			expected: "4",
+		},
+		{
+			name:     "nothing applied keeps previous",
+			rev:      engine.Revision{},
+			previous: "3",
+			expected: "3",
+		},
+		{
+			name:     "unknown after applying without target",
+			rev:      engine.Revision{Applied: 2},
+			previous: "3",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := appliedVersion(tt.rev, tt.target, tt.previous)
+			if result != tt.expected {
+				t.Errorf("appliedVersion() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestReusableToken tests when a cached IAM token is reused instead of re-minted
+func TestReusableToken(t *testing.T) {
+	cfg := awsutil.RDSAuthConfig{
+		Engine:   awsutil.EnginePostgres,
+		Host:     "db.example.com",
+		Port:     5432,
+		Username: "app",
+		DBName:   "app",
+	}
+	secretFor := func(cfg awsutil.RDSAuthConfig, expiresAt time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{TokenExpiresAtAnnotation: expiresAt.UTC().Format(time.RFC3339)},
+			},
+			Data: map[string][]byte{engine.URLKey: []byte(awsutil.BuildConnectionURL(cfg, "tok/en="))},
+		}
+	}
+	otherHost := cfg
+	otherHost.Host = "other.example.com"
+
+	tests := []struct {
+		name     string
+		secret   *corev1.Secret
+		expected bool
+	}{
+		{
+			name:     "fresh token is reused",
+			secret:   secretFor(cfg, time.Now().Add(10*time.Minute)),
+			expected: true,
+		},
+		{
+			name:     "token close to expiry is refreshed",
+			secret:   secretFor(cfg, time.Now().Add(2*time.Minute)),
+			expected: false,
+		},
+		{
+			name:     "token for another host is refreshed",
+			secret:   secretFor(otherHost, time.Now().Add(10*time.Minute)),
+			expected: false,
+		},
+		{
+			name:     "secret without expiry is refreshed",
+			secret:   &corev1.Secret{Data: map[string][]byte{engine.URLKey: []byte("postgres://app@db.example.com:5432/app")}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reusableToken(tt.secret, cfg, nil); got != tt.expected {
+				t.Errorf("reusableToken() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
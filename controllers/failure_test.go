@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
+	"github.com/subganapathy/automatic-db-upgrades/internal/fetcher"
+)
+
+// TestClassifyFailure tests mapping failed Jobs to failure types
+func TestClassifyFailure(t *testing.T) {
+	pullingPod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  fetchContainerName,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}},
+		},
+	}
+
+	fetcherPod := func(exitCode int32) *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: fetchContainerName, Command: []string{fetcherCommand}}},
+			},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:  fetchContainerName,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		jobReason string
+		pod       *corev1.Pod
+		container string
+		logs      string
+		expected  dbupgradev1alpha1.FailureType
+	}{
+		{
+			name:      "runner image cannot be pulled",
+			jobReason: batchv1.JobReasonDeadlineExceeded,
+			pod:       pullingPod,
+			container: engine.ContainerName,
+			expected:  dbupgradev1alpha1.FailureTypeImagePull,
+		},
+		{
+			name:      "deadline exceeded",
+			jobReason: batchv1.JobReasonDeadlineExceeded,
+			container: engine.ContainerName,
+			logs:      "Migrating to version 20240102",
+			expected:  dbupgradev1alpha1.FailureTypeDeadlineExceeded,
+		},
+		{
+			name:      "fetcher auth",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			pod:       fetcherPod(fetcher.ExitRegistryAuth),
+			container: fetchContainerName,
+			logs:      `fetch-migrations: failed to fetch manifest of registry.example.com/app:v2: registry access denied`,
+			expected:  dbupgradev1alpha1.FailureTypeRegistryAuth,
+		},
+		{
+			name:      "fetcher image not found",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			pod:       fetcherPod(fetcher.ExitNotFound),
+			container: fetchContainerName,
+			expected:  dbupgradev1alpha1.FailureTypeImagePull,
+		},
+		{
+			name:      "fetcher found no migrations",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			pod:       fetcherPod(fetcher.ExitEmpty),
+			container: fetchContainerName,
+			logs:      `fetch-migrations: no migration files found: directory /migrations does not exist`,
+			expected:  dbupgradev1alpha1.FailureTypeEmptyMigrations,
+		},
+		{
+			name:      "fetcher generic error",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			pod:       fetcherPod(fetcher.ExitError),
+			container: fetchContainerName,
+			logs:      `fetch-migrations: /migrations/init.sql is a link, which is not supported in the migrations directory`,
+			expected:  dbupgradev1alpha1.FailureTypeUnknown,
+		},
+		{
+			name:      "oras auth",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			container: fetchContainerName,
+			logs:      `Error: GET https://registry.example.com/v2/app/manifests/v2: UNAUTHORIZED: authentication required`,
+			expected:  dbupgradev1alpha1.FailureTypeRegistryAuth,
+		},
+		{
+			name:      "oras artifact not found",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			container: fetchContainerName,
+			logs:      `Error: MANIFEST_UNKNOWN: manifest unknown; map[Tag:v3]`,
+			expected:  dbupgradev1alpha1.FailureTypeImagePull,
+		},
+		{
+			name:      "postgres sql error",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			container: engine.ContainerName,
+			logs:      `Error: sql/migrate: execute: executing statement "CREATE TABLE users" from version "20240101": pq: relation "users" already exists`,
+			expected:  dbupgradev1alpha1.FailureTypeSQLError,
+		},
+		{
+			name:      "mysql lock wait",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			container: engine.ContainerName,
+			logs:      `Error 1205 (HY000): Lock wait timeout exceeded; try restarting transaction`,
+			expected:  dbupgradev1alpha1.FailureTypeLockTimeout,
+		},
+		{
+			name:      "liquibase changelog lock",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			container: engine.ContainerName,
+			logs:      `Could not acquire change log lock.  Currently locked by runner-1`,
+			expected:  dbupgradev1alpha1.FailureTypeLockTimeout,
+		},
+		{
+			name:      "unknown",
+			jobReason: batchv1.JobReasonBackoffLimitExceeded,
+			container: engine.ContainerName,
+			logs:      `panic: runtime error`,
+			expected:  dbupgradev1alpha1.FailureTypeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := classifyFailure(tt.jobReason, tt.pod, tt.container, tt.logs)
+			if result != tt.expected {
+				t.Errorf("classifyFailure() = %s, expected %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFailureExcerpt tests that excerpts are redacted and truncated
+func TestFailureExcerpt(t *testing.T) {
+	excerpt := failureExcerpt("Error: dial postgres://app:s3cr3t@db:5432/app failed\n")
+	if strings.Contains(excerpt, "s3cr3t") {
+		t.Errorf("failureExcerpt() leaked password: %s", excerpt)
+	}
+	if !strings.Contains(excerpt, "postgres://app:***@db:5432/app") {
+		t.Errorf("failureExcerpt() = %s, expected redacted URL", excerpt)
+	}
+
+	long := failureExcerpt(strings.Repeat("x", 2*maxFailureExcerpt) + "END")
+	if !strings.HasSuffix(long, "END") || len(long) != maxFailureExcerpt+len("...") {
+		t.Errorf("failureExcerpt() should keep the last %d bytes, got %d", maxFailureExcerpt, len(long))
+	}
+
+	// "é" is two bytes, so cutting after an odd number of bytes lands inside a rune
+	multibyte := failureExcerpt(strings.Repeat("é", maxFailureExcerpt) + "!")
+	if !utf8.ValidString(multibyte) || len(multibyte) > maxFailureExcerpt+len("...") {
+		t.Errorf("failureExcerpt() = %d bytes, valid UTF-8 = %v", len(multibyte), utf8.ValidString(multibyte))
+	}
+	if invalid := failureExcerpt("error: \xff\xfe"); !utf8.ValidString(invalid) {
+		t.Errorf("failureExcerpt() kept invalid UTF-8: %q", invalid)
+	}
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// defaultHistoryLimit is the number of runs kept in status.history when
+// spec.historyLimit is unset
+const defaultHistoryLimit = 10
+
+func historyLimit(dbUpgrade *dbupgradev1alpha1.DBUpgrade) int {
+	if dbUpgrade.Spec.HistoryLimit != nil && *dbUpgrade.Spec.HistoryLimit > 0 {
+		return int(*dbUpgrade.Spec.HistoryLimit)
+	}
+	return defaultHistoryLimit
+}
+
+// jobHistoryEntry describes a migration Job for status.history. An empty image
+// or reason is filled in by mergeHistory. Plan Jobs apply nothing and are not
+// recorded, so dry runs don't push applied runs out of the bounded history.
+func jobHistoryEntry(dbUpgrade *dbupgradev1alpha1.DBUpgrade, job *batchv1.Job, image, reason string) *dbupgradev1alpha1.MigrationHistoryEntry {
+	if isPlanJob(job) {
+		return nil
+	}
+
+	entry := &dbupgradev1alpha1.MigrationHistoryEntry{
+		JobName:  job.Name,
+		SpecHash: strings.TrimPrefix(job.Name, "dbupgrade-"+dbUpgrade.Name+"-"),
+		Image:    image,
+		Outcome:  dbupgradev1alpha1.MigrationOutcomeRunning,
+		Reason:   reason,
+	}
+
+	entry.StartTime = job.Status.StartTime
+	if entry.StartTime == nil && !job.CreationTimestamp.IsZero() {
+		created := job.CreationTimestamp
+		entry.StartTime = &created
+	}
+
+	switch {
+	case isJobSucceeded(job):
+		entry.Outcome = dbupgradev1alpha1.MigrationOutcomeSucceeded
+		entry.CompletionTime = job.Status.CompletionTime
+	case isJobFailed(job):
+		entry.Outcome = dbupgradev1alpha1.MigrationOutcomeFailed
+		entry.CompletionTime = jobFailedAt(job)
+	}
+	return entry
+}
+
+// jobFailedAt returns when the Job's Failed condition was set
+func jobFailedAt(job *batchv1.Job) *metav1.Time {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			t := c.LastTransitionTime
+			return &t
+		}
+	}
+	return nil
+}
+
+// mergeHistory upserts entry by JobName and drops the oldest entries beyond limit.
+// An empty image or start time keeps the recorded value. An empty reason keeps
+// the recorded one unless the outcome changed, in which case it is derived
+// from the outcome.
+func mergeHistory(history []dbupgradev1alpha1.MigrationHistoryEntry, entry dbupgradev1alpha1.MigrationHistoryEntry, limit int) []dbupgradev1alpha1.MigrationHistoryEntry {
+	index := -1
+	for i := range history {
+		if history[i].JobName == entry.JobName {
+			index = i
+			break
+		}
+	}
+
+	if index >= 0 {
+		old := history[index]
+		if entry.Image == "" {
+			entry.Image = old.Image
+		}
+		if entry.StartTime == nil {
+			entry.StartTime = old.StartTime
+		}
+		if entry.Reason == "" && entry.Outcome == old.Outcome {
+			entry.Reason = old.Reason
+		}
+	}
+	if entry.Reason == "" {
+		entry.Reason = outcomeReason(entry.Outcome)
+	}
+
+	if index >= 0 {
+		history[index] = entry
+	} else {
+		history = append(history, entry)
+	}
+
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
+}
+
+// outcomeReason is the reason recorded for a Job whose outcome changed unobserved
+func outcomeReason(outcome dbupgradev1alpha1.MigrationOutcome) string {
+	switch outcome {
+	case dbupgradev1alpha1.MigrationOutcomeSucceeded:
+		return dbupgradev1alpha1.ReasonMigrationComplete
+	case dbupgradev1alpha1.MigrationOutcomeFailed:
+		return dbupgradev1alpha1.ReasonJobFailed
+	}
+	return dbupgradev1alpha1.ReasonMigrationInProgress
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// TestMergeHistory tests upserting and trimming status.history
+func TestMergeHistory(t *testing.T) {
+	started := metav1.Now()
+
+	history := mergeHistory(nil, dbupgradev1alpha1.MigrationHistoryEntry{
+		JobName:   "dbupgrade-app-aaaa",
+		Image:     "app:v1",
+		StartTime: &started,
+		Outcome:   dbupgradev1alpha1.MigrationOutcomeRunning,
+		Reason:    dbupgradev1alpha1.ReasonJobPending,
+	}, 3)
+
+	// Job finished unobserved: image and start time kept, reason derived
+	history = mergeHistory(history, dbupgradev1alpha1.MigrationHistoryEntry{
+		JobName: "dbupgrade-app-aaaa",
+		Outcome: dbupgradev1alpha1.MigrationOutcomeSucceeded,
+	}, 3)
+
+	if len(history) != 1 {
+		t.Fatalf("len(history) = %d, expected 1", len(history))
+	}
+	entry := history[0]
+	if entry.Image != "app:v1" || entry.StartTime != &started {
+		t.Errorf("merged entry lost recorded fields: %+v", entry)
+	}
+	if entry.Reason != dbupgradev1alpha1.ReasonMigrationComplete {
+		t.Errorf("Reason = %s, expected %s", entry.Reason, dbupgradev1alpha1.ReasonMigrationComplete)
+	}
+
+	// Same outcome with no reason keeps the recorded reason
+	history[0].Reason = dbupgradev1alpha1.ReasonPostCheckFailed
+	history = mergeHistory(history, dbupgradev1alpha1.MigrationHistoryEntry{
+		JobName: "dbupgrade-app-aaaa",
+		Outcome: dbupgradev1alpha1.MigrationOutcomeSucceeded,
+	}, 3)
+	if history[0].Reason != dbupgradev1alpha1.ReasonPostCheckFailed {
+		t.Errorf("Reason = %s, expected %s", history[0].Reason, dbupgradev1alpha1.ReasonPostCheckFailed)
+	}
+
+	// Oldest entries are dropped beyond the limit
+	for i := 0; i < 4; i++ {
+		history = mergeHistory(history, dbupgradev1alpha1.MigrationHistoryEntry{
+			JobName: fmt.Sprintf("dbupgrade-app-%d", i),
+			Outcome: dbupgradev1alpha1.MigrationOutcomeFailed,
+		}, 3)
+	}
+	if len(history) != 3 {
+		t.Fatalf("len(history) = %d, expected 3", len(history))
+	}
+	if history[0].JobName != "dbupgrade-app-1" || history[2].JobName != "dbupgrade-app-3" {
+		t.Errorf("history = %v, expected jobs 1..3 oldest first", history)
+	}
+}
+
+// TestHistoryLimitDoesNotChangeSpecHash tests that historyLimit is excluded from the hash
+func TestHistoryLimitDoesNotChangeSpecHash(t *testing.T) {
+	spec := dbupgradev1alpha1.DBUpgradeSpec{
+		Migrations: dbupgradev1alpha1.MigrationsSpec{Image: "test:v1"},
+	}
+	limit := int32(3)
+	withLimit := spec
+	withLimit.HistoryLimit = &limit
+
+	if computeSpecHash(spec) != computeSpecHash(withLimit) {
+		t.Error("historyLimit should not change the spec hash")
+	}
+	if historyLimit(&dbupgradev1alpha1.DBUpgrade{Spec: spec}) != defaultHistoryLimit {
+		t.Errorf("historyLimit() expected default %d", defaultHistoryLimit)
+	}
+	if historyLimit(&dbupgradev1alpha1.DBUpgrade{Spec: withLimit}) != 3 {
+		t.Error("historyLimit() expected 3")
+	}
+}
+
+// TestPlanJobsAreNotRecorded tests that dry runs stay out of status.history
+func TestPlanJobsAreNotRecorded(t *testing.T) {
+	dbUpgrade := &dbupgradev1alpha1.DBUpgrade{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:        "dbupgrade-app-aaaa",
+		Annotations: map[string]string{DirectionAnnotation: directionPlan},
+	}}
+
+	if entry := jobHistoryEntry(dbUpgrade, job, "app:v1", ""); entry != nil {
+		t.Errorf("jobHistoryEntry() of a Plan Job = %+v, expected nil", entry)
+	}
+
+	job.Annotations[DirectionAnnotation] = directionUp
+	if entry := jobHistoryEntry(dbUpgrade, job, "app:v1", ""); entry == nil || entry.SpecHash != "aaaa" {
+		t.Errorf("jobHistoryEntry() of an apply Job = %+v", entry)
+	}
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+)
+
+// ResolvedImageAnnotation records on the Job the digest-pinned migrations
+// image it fetches
+const ResolvedImageAnnotation = "dbupgrade.subbug.learning/resolved-image"
+
+// resolveMigrationsImage pins migrations.image (or source.oci) to the digest
+// its tag points to now and, with migrations.cosign, verifies the signature of
+// that digest. It returns "" for sources outside a registry and if the
+// operator runs without a registry client.
+func (r *DBUpgradeReconciler) resolveMigrationsImage(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, secret *corev1.Secret) (string, error) {
+	spec := dbUpgrade.Spec.Migrations
+	ref := spec.ArtifactReference()
+	if ref == "" {
+		// ConfigMap, Secret and PVC sources are not in a registry
+		return "", nil
+	}
+	if r.RegistryClient == nil {
+		if spec.Cosign != nil {
+			return "", fmt.Errorf("%w: registry client not configured", registry.ErrVerification)
+		}
+		return "", nil
+	}
+
+	// Same credentials the fetcher gets (see registryAuthData)
+	keychain := registry.Keychain{}
+	if config, ok := secret.Data[dockerConfigKey]; ok {
+		var err error
+		if keychain, err = registry.ParseDockerConfig(config); err != nil {
+			return "", err
+		}
+	}
+
+	digest, err := r.RegistryClient.Resolve(ctx, ref, keychain)
+	if err != nil {
+		return "", err
+	}
+	pinned := registry.PinDigest(ref, digest)
+
+	if spec.Cosign != nil {
+		keyRef := spec.Cosign.PublicKeySecretRef
+		keySecret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: keyRef.Name, Namespace: dbUpgrade.Namespace}, keySecret); err != nil {
+			return "", fmt.Errorf("%w: failed to get public key secret: %v", registry.ErrVerification, err)
+		}
+		publicKey, ok := keySecret.Data[keyRef.Key]
+		if !ok {
+			return "", fmt.Errorf("%w: key %q not found in secret %q", registry.ErrVerification, keyRef.Key, keyRef.Name)
+		}
+		if err := r.RegistryClient.VerifySignature(ctx, ref, digest, publicKey, keychain); err != nil {
+			return "", err
+		}
+		log.FromContext(ctx).Info("Verified migrations image signature", "image", pinned)
+	}
+	return pinned, nil
+}
+
+// imageResolutionResult reports why no Job was created for the migrations image
+func imageResolutionResult(err error) reconcileResult {
+	if errors.Is(err, registry.ErrVerification) {
+		return reconcileResult{
+			ready:           false,
+			readyReason:     dbupgradev1alpha1.ReasonImageVerificationFailed,
+			readyMessage:    err.Error(),
+			progressing:     false,
+			progressReason:  dbupgradev1alpha1.ReasonImageVerificationFailed,
+			progressMessage: "Refusing to run an image without a valid signature",
+			requeueAfter:    5 * time.Minute,
+			event:           &eventInfo{corev1.EventTypeWarning, "ImageVerificationFailed", err.Error()},
+		}
+	}
+	return reconcileResult{
+		ready:           false,
+		readyReason:     dbupgradev1alpha1.ReasonImageResolutionFailed,
+		readyMessage:    "Failed to resolve migrations image",
+		progressing:     false,
+		progressReason:  dbupgradev1alpha1.ReasonImageResolutionFailed,
+		progressMessage: err.Error(),
+		requeueAfter:    30 * time.Second,
+	}
+}
+
+// jobImage returns where a Job fetches its migrations from: the pinned image
+// if it was resolved, else the configured image or source
+func jobImage(dbUpgrade *dbupgradev1alpha1.DBUpgrade, job *batchv1.Job) string {
+	if image := job.Annotations[ResolvedImageAnnotation]; image != "" {
+		return image
+	}
+	return migrationsSourceName(dbUpgrade)
+}
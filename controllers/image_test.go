@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+)
+
+// TestImageResolutionResult tests that unsigned images are refused with
+// their own reason while registry errors are retried
+func TestImageResolutionResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		reason      string
+		expectEvent bool
+	}{
+		{
+			name:        "signature mismatch",
+			err:         fmt.Errorf("%w: no signature matches the public key", registry.ErrVerification),
+			reason:      dbupgradev1alpha1.ReasonImageVerificationFailed,
+			expectEvent: true,
+		},
+		{
+			name:   "registry unreachable",
+			err:    fmt.Errorf("failed to resolve ghcr.io/acme/migrations:v2: 503 Service Unavailable"),
+			reason: dbupgradev1alpha1.ReasonImageResolutionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := imageResolutionResult(tt.err)
+			if result.ready || result.readyReason != tt.reason {
+				t.Errorf("imageResolutionResult() ready = %v, reason = %s, expected reason %s", result.ready, result.readyReason, tt.reason)
+			}
+			if (result.event != nil) != tt.expectEvent {
+				t.Errorf("imageResolutionResult() event = %v, expectEvent %v", result.event, tt.expectEvent)
+			}
+		})
+	}
+}
+
+// TestJobImage tests that the pinned image recorded on the Job wins
+func TestJobImage(t *testing.T) {
+	dbUpgrade := &dbupgradev1alpha1.DBUpgrade{
+		Spec: dbupgradev1alpha1.DBUpgradeSpec{
+			Migrations: dbupgradev1alpha1.MigrationsSpec{Image: "ghcr.io/acme/migrations:v2"},
+		},
+	}
+	pinned := "ghcr.io/acme/migrations@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+
+	if got := jobImage(dbUpgrade, &batchv1.Job{}); got != dbUpgrade.Spec.Migrations.Image {
+		t.Errorf("jobImage() without annotation = %s, expected %s", got, dbUpgrade.Spec.Migrations.Image)
+	}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ResolvedImageAnnotation: pinned}}}
+	if got := jobImage(dbUpgrade, job); got != pinned {
+		t.Errorf("jobImage() = %s, expected %s", got, pinned)
+	}
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxLogBytes caps how much runner output is read back from a Job pod.
+// ConfigMaps are limited to 1MiB, so stay well below that.
+const maxLogBytes = int64(512 * 1024)
+
+// getJobPod returns the (single, backoffLimit=0) pod created for a Job
+func (r *DBUpgradeReconciler) getJobPod(ctx context.Context, job *batchv1.Job) (*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list pods for Job %s: %w", job.Name, err)
+	}
+	if len(podList.Items) == 0 {
+		return nil, fmt.Errorf("no pods found for Job %s", job.Name)
+	}
+
+	// Prefer the most recently created pod
+	pod := &podList.Items[0]
+	for i := range podList.Items {
+		if podList.Items[i].CreationTimestamp.After(pod.CreationTimestamp.Time) {
+			pod = &podList.Items[i]
+		}
+	}
+	return pod, nil
+}
+
+// getContainerLogs reads the logs of a container in the Job's pod.
+// tailLines <= 0 reads the whole log (still capped at maxLogBytes).
+func (r *DBUpgradeReconciler) getContainerLogs(ctx context.Context, job *batchv1.Job, container string, tailLines int64) (string, error) {
+	if r.RestConfig == nil {
+		return "", fmt.Errorf("RestConfig not available for reading pod logs")
+	}
+
+	pod, err := r.getJobPod(ctx, job)
+	if err != nil {
+		return "", err
+	}
+
+	clientset, err := kubernetes.NewForConfig(r.RestConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	limitBytes := maxLogBytes
+	opts := &corev1.PodLogOptions{Container: container, LimitBytes: &limitBytes}
+	if tailLines > 0 {
+		opts.TailLines = &tailLines
+	}
+
+	raw, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs of %s/%s: %w", pod.Name, container, err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	awsutil "github.com/subganapathy/automatic-db-upgrades/internal/aws"
+)
+
+// RegistryTokenExpiresAtAnnotation records on the operator Secret when the
+// ECR token in its docker config expires
+const RegistryTokenExpiresAtAnnotation = "dbupgrade.subbug.learning/registry-token-expires-at"
+
+// registryTokenRefreshMargin is how long before expiry an ECR token (valid
+// for 12 hours) is re-minted
+const registryTokenRefreshMargin = time.Hour
+
+const (
+	// dockerConfigKey is the merged docker config in the operator-managed Secret
+	dockerConfigKey = "docker-config.json"
+
+	// dockerConfigVolume projects dockerConfigKey into the fetch container
+	dockerConfigVolume = "docker-config"
+
+	// dockerConfigMountPath is DOCKER_CONFIG for the fetch container
+	dockerConfigMountPath = "/etc/dbupgrade/docker"
+)
+
+// dockerConfig is the part of a docker config.json the fetch container reads
+type dockerConfig struct {
+	Auths map[string]json.RawMessage `json:"auths"`
+}
+
+// registryAuthData builds the docker config the fetch container uses to pull
+// the migrations image: the entries of migrations.imagePullSecrets plus, with
+// migrations.ecr, an ECR token reused from secret until it is due for refresh
+func (r *DBUpgradeReconciler) registryAuthData(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, secret *corev1.Secret) ([]byte, map[string]string, error) {
+	spec := dbUpgrade.Spec.Migrations
+	if len(spec.ImagePullSecrets) == 0 && spec.ECR == nil {
+		return nil, nil, nil
+	}
+
+	config := dockerConfig{Auths: map[string]json.RawMessage{}}
+	for _, ref := range spec.ImagePullSecrets {
+		pullSecret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: dbUpgrade.Namespace}, pullSecret); err != nil {
+			return nil, nil, fmt.Errorf("failed to get image pull secret: %w", err)
+		}
+		auths, err := dockerConfigAuths(pullSecret)
+		if err != nil {
+			return nil, nil, err
+		}
+		for registry, entry := range auths {
+			config.Auths[registry] = entry
+		}
+	}
+
+	annotations := map[string]string{}
+	if spec.ECR != nil {
+		registry := registryHost(spec.ArtifactReference())
+		region, ok := awsutil.ECRRegion(registry)
+		if !ok {
+			return nil, nil, fmt.Errorf("migrations.ecr requires a private ECR image, got registry %s", registry)
+		}
+
+		entry, expiresAt, ok := reusableRegistryAuth(secret, registry)
+		if !ok {
+			if r.AWSClientManager == nil {
+				return nil, nil, fmt.Errorf("AWS client manager not configured - AWS support is disabled")
+			}
+			creds, err := r.AWSClientManager.GetECRAuthorizationToken(ctx, awsutil.ECRAuthConfig{
+				Region:     region,
+				RoleArn:    spec.ECR.RoleArn,
+				ExternalID: fmt.Sprintf("%s/%s", dbUpgrade.Namespace, dbUpgrade.Name),
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			auth := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+			if entry, err = json.Marshal(map[string]string{"auth": auth}); err != nil {
+				return nil, nil, err
+			}
+			expiresAt = creds.ExpiresAt
+			log.FromContext(ctx).Info("Generated ECR authorization token",
+				"registry", registry,
+				"expiresAt", expiresAt.UTC().Format(time.RFC3339))
+		}
+		config.Auths[registry] = entry
+		annotations[RegistryTokenExpiresAtAnnotation] = expiresAt.UTC().Format(time.RFC3339)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, annotations, nil
+}
+
+// dockerConfigAuths reads the registry entries of a kubernetes.io/dockerconfigjson
+// or legacy kubernetes.io/dockercfg Secret
+func dockerConfigAuths(secret *corev1.Secret) (map[string]json.RawMessage, error) {
+	if raw, ok := secret.Data[corev1.DockerConfigJsonKey]; ok {
+		var config dockerConfig
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, fmt.Errorf("secret %q has an invalid %s", secret.Name, corev1.DockerConfigJsonKey)
+		}
+		return config.Auths, nil
+	}
+	if raw, ok := secret.Data[corev1.DockerConfigKey]; ok {
+		var auths map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &auths); err != nil {
+			return nil, fmt.Errorf("secret %q has an invalid %s", secret.Name, corev1.DockerConfigKey)
+		}
+		return auths, nil
+	}
+	return nil, fmt.Errorf("secret %q has neither %s nor %s", secret.Name, corev1.DockerConfigJsonKey, corev1.DockerConfigKey)
+}
+
+// reusableRegistryAuth returns the ECR entry for registry from the operator
+// Secret if its token is not yet due for refresh
+func reusableRegistryAuth(secret *corev1.Secret, registry string) (json.RawMessage, time.Time, bool) {
+	if secret == nil {
+		return nil, time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, secret.Annotations[RegistryTokenExpiresAtAnnotation])
+	if err != nil || time.Until(expiresAt) <= registryTokenRefreshMargin {
+		return nil, time.Time{}, false
+	}
+	var config dockerConfig
+	if err := json.Unmarshal(secret.Data[dockerConfigKey], &config); err != nil {
+		return nil, time.Time{}, false
+	}
+	entry, ok := config.Auths[registry]
+	return entry, expiresAt, ok
+}
+
+// registryHost returns the registry of an image reference. References
+// without one (postgres:16, library/postgres) are on Docker Hub.
+func registryHost(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "index.docker.io"
+}
+
+// dockerConfigVolumeFor projects the docker config of the operator Secret
+// for the fetch container
+func dockerConfigVolumeFor(secretName string) (corev1.Volume, corev1.VolumeMount, corev1.EnvVar) {
+	volume := corev1.Volume{
+		Name: dockerConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items:      []corev1.KeyToPath{{Key: dockerConfigKey, Path: "config.json"}},
+			},
+		},
+	}
+	mount := corev1.VolumeMount{Name: dockerConfigVolume, MountPath: dockerConfigMountPath, ReadOnly: true}
+	env := corev1.EnvVar{Name: "DOCKER_CONFIG", Value: dockerConfigMountPath}
+	return volume, mount, env
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRegistryHost tests the registry derived from migrations.image
+func TestRegistryHost(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "123456789012.dkr.ecr.us-east-1.amazonaws.com/app/migrations:v1", expected: "123456789012.dkr.ecr.us-east-1.amazonaws.com"},
+		{image: "ghcr.io/acme/migrations@sha256:abc", expected: "ghcr.io"},
+		{image: "registry.local:5000/migrations:v1", expected: "registry.local:5000"},
+		{image: "localhost/migrations:v1", expected: "localhost"},
+		{image: "acme/migrations:v1", expected: "index.docker.io"},
+		{image: "migrations:v1", expected: "index.docker.io"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := registryHost(tt.image); got != tt.expected {
+				t.Errorf("registryHost(%q) = %s, expected %s", tt.image, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDockerConfigAuths tests reading both image pull secret formats
+func TestDockerConfigAuths(t *testing.T) {
+	entry := `{"auth":"dXNlcjpwYXNz"}`
+
+	tests := []struct {
+		name    string
+		data    map[string][]byte
+		wantErr bool
+	}{
+		{
+			name: "dockerconfigjson",
+			data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"ghcr.io":` + entry + `}}`)},
+		},
+		{
+			name: "legacy dockercfg",
+			data: map[string][]byte{corev1.DockerConfigKey: []byte(`{"ghcr.io":` + entry + `}`)},
+		},
+		{
+			name:    "not a pull secret",
+			data:    map[string][]byte{"password": []byte("secret")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pull"}, Data: tt.data}
+			auths, err := dockerConfigAuths(secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dockerConfigAuths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := string(auths["ghcr.io"]); got != entry {
+				t.Errorf("dockerConfigAuths()[ghcr.io] = %s, expected %s", got, entry)
+			}
+		})
+	}
+}
+
+// TestReusableRegistryAuth tests when a stored ECR token is reused
+func TestReusableRegistryAuth(t *testing.T) {
+	registry := "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+	config, _ := json.Marshal(dockerConfig{Auths: map[string]json.RawMessage{
+		registry: json.RawMessage(`{"auth":"QVdTOnRva2Vu"}`),
+	}})
+	secretExpiring := func(d time.Duration) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				RegistryTokenExpiresAtAnnotation: time.Now().Add(d).UTC().Format(time.RFC3339),
+			}},
+			Data: map[string][]byte{dockerConfigKey: config},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		secret   *corev1.Secret
+		registry string
+		expected bool
+	}{
+		{name: "no secret yet", secret: nil, registry: registry, expected: false},
+		{name: "fresh token", secret: secretExpiring(6 * time.Hour), registry: registry, expected: true},
+		{name: "token due for refresh", secret: secretExpiring(30 * time.Minute), registry: registry, expected: false},
+		{name: "image moved to another registry", secret: secretExpiring(6 * time.Hour), registry: "210987654321.dkr.ecr.eu-west-1.amazonaws.com", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, got := reusableRegistryAuth(tt.secret, tt.registry); got != tt.expected {
+				t.Errorf("reusableRegistryAuth() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
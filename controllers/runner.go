@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// applyRunnerOverrides merges spec.runner pod settings into the Job's pod
+// template. Containers, volumes and the restart policy stay owned by the
+// operator; labels and annotations are added alongside the operator's own.
+func applyRunnerOverrides(template *corev1.PodTemplateSpec, runner *dbupgradev1alpha1.RunnerSpec) {
+	if runner == nil {
+		return
+	}
+
+	template.Labels = mergeStringMaps(template.Labels, runner.PodLabels)
+	template.Annotations = mergeStringMaps(template.Annotations, runner.PodAnnotations)
+
+	podSpec := &template.Spec
+	if len(runner.NodeSelector) > 0 {
+		podSpec.NodeSelector = mergeStringMaps(podSpec.NodeSelector, runner.NodeSelector)
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, runner.Tolerations...)
+	podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, runner.ImagePullSecrets...)
+	if runner.Affinity != nil {
+		podSpec.Affinity = runner.Affinity.DeepCopy()
+	}
+	if runner.PriorityClassName != "" {
+		podSpec.PriorityClassName = runner.PriorityClassName
+	}
+	if runner.ServiceAccountName != "" {
+		podSpec.ServiceAccountName = runner.ServiceAccountName
+	}
+	if runner.PodSecurityContext != nil {
+		podSpec.SecurityContext = runner.PodSecurityContext.DeepCopy()
+	}
+
+	// Admission policies that require limits or a restricted security
+	// context check init containers too
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			if runner.Resources != nil {
+				containers[i].Resources = *runner.Resources.DeepCopy()
+			}
+			if runner.SecurityContext != nil {
+				containers[i].SecurityContext = runner.SecurityContext.DeepCopy()
+			}
+		}
+	}
+}
+
+// mergeStringMaps returns base with overrides added; keys already in base win
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+	out := make(map[string]string, len(base)+len(overrides))
+	for k, v := range overrides {
+		out[k] = v
+	}
+	for k, v := range base {
+		out[k] = v
+	}
+	return out
+}
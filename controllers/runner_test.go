@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// TestApplyRunnerOverrides tests that spec.runner settings reach the pod template
+func TestApplyRunnerOverrides(t *testing.T) {
+	runAsNonRoot := true
+	runner := &dbupgradev1alpha1.RunnerSpec{
+		Resources: &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		NodeSelector:       map[string]string{"pool": "batch"},
+		Tolerations:        []corev1.Toleration{{Key: "batch", Operator: corev1.TolerationOpExists}},
+		PriorityClassName:  "low",
+		ServiceAccountName: "migrator",
+		ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "registry"}},
+		PodLabels:          map[string]string{"team": "payments", "app": "override"},
+		PodSecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot},
+	}
+	template := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "dbupgrade"}},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: fetchContainerName}},
+			Containers:     []corev1.Container{{Name: "migrate"}},
+		},
+	}
+
+	applyRunnerOverrides(template, runner)
+
+	if template.Labels["team"] != "payments" || template.Labels["app"] != "dbupgrade" {
+		t.Errorf("Labels = %v, expected team added and app kept", template.Labels)
+	}
+	spec := template.Spec
+	if spec.NodeSelector["pool"] != "batch" || len(spec.Tolerations) != 1 || len(spec.ImagePullSecrets) != 1 {
+		t.Errorf("scheduling settings not applied: %+v", spec)
+	}
+	if spec.PriorityClassName != "low" || spec.ServiceAccountName != "migrator" {
+		t.Errorf("PriorityClassName = %q, ServiceAccountName = %q", spec.PriorityClassName, spec.ServiceAccountName)
+	}
+	if spec.SecurityContext == nil || !*spec.SecurityContext.RunAsNonRoot {
+		t.Errorf("SecurityContext = %v, expected runAsNonRoot", spec.SecurityContext)
+	}
+	for _, c := range append(spec.InitContainers, spec.Containers...) {
+		if c.Resources.Limits.Memory().String() != "256Mi" {
+			t.Errorf("container %s limits = %v, expected memory 256Mi", c.Name, c.Resources.Limits)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
+)
+
+const (
+	// fetcherCommand is cmd/fetcher in FetcherImage
+	fetcherCommand = "/fetcher"
+
+	// fetchMountPath is where the fetch container sees the migrations volume
+	fetchMountPath = "/shared"
+
+	// migrationsSourceVolume is a ConfigMap or Secret source, copied by the
+	// fetch container
+	migrationsSourceVolume = "migrations-source"
+	sourceMountPath        = "/source"
+)
+
+// migrationsFetch returns the pod volumes holding the migration files and the
+// init container that fills the migrations volume, if the source needs one.
+// ref is the (pinned) image or artifact to pull.
+func migrationsFetch(dbUpgrade *dbupgradev1alpha1.DBUpgrade, ref string) ([]corev1.Volume, []corev1.Container) {
+	source := dbUpgrade.Spec.Migrations.Source
+	dir := migrationsDirOrDefault(dbUpgrade)
+	emptyDir := corev1.Volume{
+		Name:         engine.MigrationsVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}
+	fetchMount := corev1.VolumeMount{Name: engine.MigrationsVolume, MountPath: fetchMountPath}
+
+	switch {
+	case source == nil || source.OCI != nil:
+		// The fetcher picks the image platform it was built for, which is the
+		// node's; artifacts have no platform
+		flag := "--image"
+		if source != nil {
+			flag = "--artifact"
+		}
+		args := []string{flag, ref, "--dir", dir, "--output", fetchMountPath}
+		if AllowInsecureRegistries {
+			args = append(args, "--insecure")
+		}
+		return []corev1.Volume{emptyDir}, []corev1.Container{{
+			Name:         fetchContainerName,
+			Image:        FetcherImage,
+			Command:      []string{fetcherCommand},
+			Args:         args,
+			VolumeMounts: []corev1.VolumeMount{fetchMount},
+		}}
+
+	case source.PersistentVolumeClaim != nil:
+		// The runner reads the files in place
+		return []corev1.Volume{{
+			Name: engine.MigrationsVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: source.PersistentVolumeClaim.ClaimName,
+					ReadOnly:  true,
+				},
+			},
+		}}, nil
+
+	default:
+		// ConfigMap and Secret volumes are symlink farms with hidden ..data
+		// directories, which recursive scanners (Flyway) pick up twice; copy
+		// the plain files instead of mounting the volume into the runner
+		sourceVolume := corev1.Volume{Name: migrationsSourceVolume}
+		if source.ConfigMap != nil {
+			sourceVolume.ConfigMap = &corev1.ConfigMapVolumeSource{LocalObjectReference: *source.ConfigMap}
+		} else {
+			sourceVolume.Secret = &corev1.SecretVolumeSource{SecretName: source.Secret.Name}
+		}
+		return []corev1.Volume{emptyDir, sourceVolume}, []corev1.Container{{
+			Name:    fetchContainerName,
+			Image:   FetcherImage,
+			Command: []string{fetcherCommand},
+			Args:    []string{"--source-dir", sourceMountPath, "--dir", dir, "--output", fetchMountPath},
+			VolumeMounts: []corev1.VolumeMount{
+				fetchMount,
+				{Name: migrationsSourceVolume, MountPath: sourceMountPath, ReadOnly: true},
+			},
+		}}
+	}
+}
+
+// migrationsSourceName describes where a Job's migration files came from,
+// for status and history: the image, or the source kind and name
+func migrationsSourceName(dbUpgrade *dbupgradev1alpha1.DBUpgrade) string {
+	source := dbUpgrade.Spec.Migrations.Source
+	switch {
+	case source == nil:
+		return dbUpgrade.Spec.Migrations.Image
+	case source.ConfigMap != nil:
+		return "configmap/" + source.ConfigMap.Name
+	case source.Secret != nil:
+		return "secret/" + source.Secret.Name
+	case source.PersistentVolumeClaim != nil:
+		return "pvc/" + source.PersistentVolumeClaim.ClaimName
+	case source.OCI != nil:
+		return source.OCI.Reference
+	}
+	return ""
+}
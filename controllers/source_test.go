@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
+)
+
+// TestMigrationsFetch tests the volumes and fetch container of each source
+func TestMigrationsFetch(t *testing.T) {
+	tests := []struct {
+		name         string
+		migrations   dbupgradev1alpha1.MigrationsSpec
+		ref          string
+		volumes      []string
+		fetchImage   string
+		fetchCommand string
+	}{
+		{
+			name:         "image is extracted by the fetcher",
+			migrations:   dbupgradev1alpha1.MigrationsSpec{Image: "ghcr.io/acme/migrations:v2", Dir: "/db"},
+			ref:          "ghcr.io/acme/migrations@sha256:abc",
+			volumes:      []string{engine.MigrationsVolume},
+			fetchImage:   FetcherImage,
+			fetchCommand: "--image ghcr.io/acme/migrations@sha256:abc --dir /db --output /shared",
+		},
+		{
+			name: "configMap files are copied",
+			migrations: dbupgradev1alpha1.MigrationsSpec{Source: &dbupgradev1alpha1.MigrationsSourceSpec{
+				ConfigMap: &corev1.LocalObjectReference{Name: "app-migrations"},
+			}},
+			volumes:      []string{engine.MigrationsVolume, migrationsSourceVolume},
+			fetchImage:   FetcherImage,
+			fetchCommand: "--source-dir /source --dir /migrations --output /shared",
+		},
+		{
+			name: "oci artifact is written by the fetcher",
+			migrations: dbupgradev1alpha1.MigrationsSpec{Source: &dbupgradev1alpha1.MigrationsSourceSpec{
+				OCI: &dbupgradev1alpha1.OCISourceSpec{Reference: "ghcr.io/acme/migrations-sql:v2"},
+			}},
+			ref:          "ghcr.io/acme/migrations-sql@sha256:def",
+			volumes:      []string{engine.MigrationsVolume},
+			fetchImage:   FetcherImage,
+			fetchCommand: "--artifact ghcr.io/acme/migrations-sql@sha256:def --dir /migrations --output /shared",
+		},
+		{
+			name: "pvc is mounted without a fetch container",
+			migrations: dbupgradev1alpha1.MigrationsSpec{Source: &dbupgradev1alpha1.MigrationsSourceSpec{
+				PersistentVolumeClaim: &dbupgradev1alpha1.PVCSourceSpec{ClaimName: "migrations"},
+			}},
+			volumes: []string{engine.MigrationsVolume},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbUpgrade := &dbupgradev1alpha1.DBUpgrade{Spec: dbupgradev1alpha1.DBUpgradeSpec{Migrations: tt.migrations}}
+			volumes, initContainers := migrationsFetch(dbUpgrade, tt.ref)
+
+			var names []string
+			for _, v := range volumes {
+				names = append(names, v.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.volumes, ",") {
+				t.Errorf("volumes = %v, expected %v", names, tt.volumes)
+			}
+
+			if tt.fetchImage == "" {
+				if len(initContainers) != 0 {
+					t.Errorf("expected no fetch container, got %d", len(initContainers))
+				}
+				if pvc := volumes[0].PersistentVolumeClaim; pvc == nil || !pvc.ReadOnly {
+					t.Errorf("expected a read-only PVC volume, got %+v", volumes[0].VolumeSource)
+				}
+				return
+			}
+			if len(initContainers) != 1 || initContainers[0].Name != fetchContainerName {
+				t.Fatalf("expected the %s container, got %+v", fetchContainerName, initContainers)
+			}
+			fetch := initContainers[0]
+			if fetch.Image != tt.fetchImage {
+				t.Errorf("fetch image = %s, expected %s", fetch.Image, tt.fetchImage)
+			}
+			if got := strings.Join(fetch.Args, " "); !strings.Contains(got, tt.fetchCommand) {
+				t.Errorf("fetch args = %q, expected to contain %q", got, tt.fetchCommand)
+			}
+		})
+	}
+}
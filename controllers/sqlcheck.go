@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/checks"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
+)
+
+// CheckPhaseLabel marks the Jobs running SQL checks with their phase, which
+// keeps them apart from the migration Job
+const CheckPhaseLabel = "dbupgrade.subbug.learning/check-phase"
+
+// Annotations recording the outcome of a finished SQL check Job, so its logs
+// are read once
+const (
+	CheckResultAnnotation  = "dbupgrade.subbug.learning/check-result"
+	CheckMessageAnnotation = "dbupgrade.subbug.learning/check-message"
+)
+
+const (
+	checkPhasePre  = "pre"
+	checkPhasePost = "post"
+
+	checkPassed = "passed"
+	checkFailed = "failed"
+)
+
+// sqlCheckRetryInterval is how long a failed SQL check Job is kept before it
+// is replaced by a new run
+const sqlCheckRetryInterval = 60 * time.Second
+
+// sqlCheckJobName is the SQL check Job of a phase for the migration Job jobName.
+// The spec hash in jobName starts new checks when the spec changes.
+func sqlCheckJobName(jobName, phase string) string {
+	return fmt.Sprintf("%s-%scheck", jobName, phase)
+}
+
+// runSQLChecks runs the SQL checks of a phase in a check Job and returns a
+// ready result once all of them passed. A failed run is retried after
+// sqlCheckRetryInterval.
+func (r *DBUpgradeReconciler) runSQLChecks(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, jobName, phase string, sqlChecks []dbupgradev1alpha1.SQLCheck, failReason string) reconcileResult {
+	logger := log.FromContext(ctx)
+
+	// Results are read from the check containers' logs
+	if r.RestConfig == nil {
+		logger.Info("RestConfig not available for SQL checks, skipping")
+		return reconcileResult{ready: true}
+	}
+
+	eventReason := "PreCheckFailed"
+	if phase == checkPhasePost {
+		eventReason = "PostCheckFailed"
+	}
+	errorResult := func(message string, err error) reconcileResult {
+		logger.Error(err, message)
+		return reconcileResult{
+			ready:           false,
+			readyReason:     failReason,
+			readyMessage:    message,
+			progressing:     false,
+			progressReason:  failReason,
+			progressMessage: err.Error(),
+			requeueAfter:    30 * time.Second,
+		}
+	}
+	runningResult := func(message string) reconcileResult {
+		return reconcileResult{
+			ready:           false,
+			readyReason:     dbupgradev1alpha1.ReasonSQLCheckRunning,
+			readyMessage:    message,
+			progressing:     true,
+			progressReason:  dbupgradev1alpha1.ReasonSQLCheckRunning,
+			progressMessage: message,
+			requeueAfter:    5 * time.Second,
+		}
+	}
+
+	checkJobName := sqlCheckJobName(jobName, phase)
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: checkJobName, Namespace: dbUpgrade.Namespace}, job)
+	if errors.IsNotFound(err) {
+		if err := r.deleteStaleCheckJobs(ctx, dbUpgrade, phase, checkJobName); err != nil {
+			return errorResult("Failed to delete stale SQL check Jobs", err)
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: migrationSecretName(dbUpgrade), Namespace: dbUpgrade.Namespace}, secret); err != nil {
+			return errorResult("Failed to get migration secret", err)
+		}
+		job, err := sqlCheckJob(dbUpgrade, checkJobName, phase, secret, sqlChecks)
+		if err != nil {
+			return errorResult("Failed to render SQL check Job", err)
+		}
+		if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+			return errorResult("Failed to create SQL check Job", err)
+		}
+		logger.Info("Created SQL check Job", "job", checkJobName, "phase", phase)
+		return runningResult(fmt.Sprintf("Running %d SQL %scheck(s) in Job %s", len(sqlChecks), phase, checkJobName))
+	}
+	if err != nil {
+		return errorResult("Failed to get SQL check Job", err)
+	}
+
+	switch job.Annotations[CheckResultAnnotation] {
+	case checkPassed:
+		return reconcileResult{ready: true}
+	case checkFailed:
+		return r.retrySQLChecks(ctx, job, failReason, job.Annotations[CheckMessageAnnotation], nil)
+	}
+
+	if !isJobSucceeded(job) && !isJobFailed(job) {
+		return runningResult(fmt.Sprintf("Waiting for SQL %scheck Job %s", phase, checkJobName))
+	}
+
+	result, err := r.evaluateSQLCheckJob(ctx, job, sqlChecks)
+	if err != nil {
+		return errorResult("Failed to read SQL check results", err)
+	}
+
+	outcome := checkPassed
+	if !result.Passed {
+		outcome = checkFailed
+	}
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[CheckResultAnnotation] = outcome
+	job.Annotations[CheckMessageAnnotation] = result.Message
+	if err := r.Update(ctx, job); err != nil {
+		return errorResult("Failed to record SQL check result", err)
+	}
+
+	if !result.Passed {
+		logger.Info("SQL check failed", "phase", phase, "message", result.Message)
+		return r.retrySQLChecks(ctx, job, failReason, result.Message, &eventInfo{corev1.EventTypeWarning, eventReason, result.Message})
+	}
+	logger.Info("SQL checks passed", "phase", phase, "message", result.Message)
+	return reconcileResult{ready: true}
+}
+
+// retrySQLChecks reports a failed check Job and deletes it once
+// sqlCheckRetryInterval has passed, so the next reconcile runs the checks again
+func (r *DBUpgradeReconciler) retrySQLChecks(ctx context.Context, job *batchv1.Job, failReason, message string, event *eventInfo) reconcileResult {
+	result := reconcileResult{
+		ready:           false,
+		readyReason:     failReason,
+		readyMessage:    message,
+		progressing:     false,
+		progressReason:  failReason,
+		progressMessage: message,
+		event:           event,
+	}
+
+	remaining := sqlCheckRetryInterval - time.Since(jobFinishedAt(job))
+	if remaining > 0 {
+		result.requeueAfter = remaining
+		return result
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "Failed to delete failed SQL check Job", "job", job.Name)
+	}
+	result.requeueAfter = 2 * time.Second
+	return result
+}
+
+// evaluateSQLCheckJob reads the output of each check container of a finished
+// Job and stops at the first failed check
+func (r *DBUpgradeReconciler) evaluateSQLCheckJob(ctx context.Context, job *batchv1.Job, sqlChecks []dbupgradev1alpha1.SQLCheck) (*checks.MetricCheckResult, error) {
+	pod, err := r.getJobPod(ctx, job)
+	if err != nil {
+		if isJobFailed(job) {
+			return &checks.MetricCheckResult{Message: fmt.Sprintf("SQL check Job %s failed (%s)", job.Name, jobFailedReason(job))}, nil
+		}
+		return nil, err
+	}
+	exitCodes := map[string]int32{}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			exitCodes[status.Name] = status.State.Terminated.ExitCode
+		}
+	}
+
+	for i, check := range sqlChecks {
+		container := engine.SQLCheckContainerName(i)
+		exitCode, terminated := exitCodes[container]
+		if !terminated {
+			return &checks.MetricCheckResult{Message: fmt.Sprintf("SQL check %s did not finish (%s)", check.Name, jobFailedReason(job))}, nil
+		}
+
+		output, err := r.getContainerLogs(ctx, job, container, 0)
+		if err != nil {
+			return nil, err
+		}
+		if exitCode != 0 {
+			return &checks.MetricCheckResult{Message: fmt.Sprintf("SQL check %s failed: %s", check.Name, failureExcerpt(output))}, nil
+		}
+
+		result, err := checks.EvaluateSQLCheck(check, output)
+		if err != nil {
+			return &checks.MetricCheckResult{Message: fmt.Sprintf("SQL check %s: %v", check.Name, err)}, nil
+		}
+		if !result.Passed {
+			return result, nil
+		}
+	}
+
+	return &checks.MetricCheckResult{
+		Passed:  true,
+		Message: fmt.Sprintf("All %d SQL check(s) passed", len(sqlChecks)),
+	}, nil
+}
+
+// deleteStaleCheckJobs deletes the check Jobs of a phase left by an earlier spec
+func (r *DBUpgradeReconciler) deleteStaleCheckJobs(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, phase, current string) error {
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.InNamespace(dbUpgrade.Namespace), client.MatchingLabels{CheckPhaseLabel: phase}); err != nil {
+		return fmt.Errorf("failed to list SQL check Jobs: %w", err)
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if job.Name == current || !metav1.IsControlledBy(job, dbUpgrade) {
+			continue
+		}
+		if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlCheckJob renders the Job running sqlChecks with the client matching the
+// connection URL in the migration secret. Only the client's connection file
+// and the TLS files are mounted.
+func sqlCheckJob(dbUpgrade *dbupgradev1alpha1.DBUpgrade, name, phase string, migrationSecret *corev1.Secret, sqlChecks []dbupgradev1alpha1.SQLCheck) (*batchv1.Job, error) {
+	if _, ok := migrationSecret.Data[engine.SQLCheckConfigKey]; !ok {
+		return nil, fmt.Errorf("migration secret has no %s key", engine.SQLCheckConfigKey)
+	}
+	containers, err := engine.SQLCheckContainers(string(migrationSecret.Data[engine.URLKey]), sqlChecks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave the statement timeouts room for pulling the client image
+	var maxTimeout int64
+	for _, check := range sqlChecks {
+		if int64(check.TimeoutSeconds) > maxTimeout {
+			maxTimeout = int64(check.TimeoutSeconds)
+		}
+	}
+	activeDeadlineSeconds := maxTimeout*int64(len(sqlChecks)) + 120
+
+	readOnly := int32(0444)
+	volumes := []corev1.Volume{{
+		Name: engine.ConnectionVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: migrationSecret.Name,
+				Items:      []corev1.KeyToPath{{Key: engine.SQLCheckConfigKey, Path: engine.SQLCheckConfigKey, Mode: &readOnly}},
+			},
+		},
+	}}
+	if dbUpgrade.Spec.Database.TLS != nil {
+		volume, mount := databaseTLSVolumeFor(dbUpgrade, migrationSecret.Name)
+		volumes = append(volumes, volume)
+		for i := range containers {
+			containers[i].VolumeMounts = append(containers[i].VolumeMounts, mount)
+		}
+	}
+
+	backoffLimit := int32(0)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: dbUpgrade.Namespace,
+			Labels:    map[string]string{CheckPhaseLabel: phase},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "dbupgrade.subbug.learning/v1alpha1",
+				Kind:               "DBUpgrade",
+				Name:               dbUpgrade.Name,
+				UID:                dbUpgrade.UID,
+				Controller:         boolPtr(true),
+				BlockOwnerDeletion: boolPtr(true),
+			}},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Volumes:       volumes,
+					Containers:    containers,
+				},
+			},
+		},
+	}
+
+	// Scheduling, security context and resources follow the migration Job
+	applyRunnerOverrides(&job.Spec.Template, dbUpgrade.Spec.Runner)
+	ensureClientKeyReadable(&job.Spec.Template.Spec, dbUpgrade)
+	return job, nil
+}
+
+// isCheckJob reports whether job runs SQL checks rather than the migration
+func isCheckJob(job *batchv1.Job) bool {
+	_, ok := job.Labels[CheckPhaseLabel]
+	return ok
+}
+
+// jobFinishedAt returns when a succeeded or failed Job finished
+func jobFinishedAt(job *batchv1.Job) time.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return c.LastTransitionTime.Time
+		}
+	}
+	return job.CreationTimestamp.Time
+}
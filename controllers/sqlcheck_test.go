@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
+)
+
+// TestSQLCheckJob tests that the check Job mounts only the client connection
+// file and TLS files, and is kept apart from the migration Job
+func TestSQLCheckJob(t *testing.T) {
+	dbUpgrade := &dbupgradev1alpha1.DBUpgrade{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"},
+		Spec: dbupgradev1alpha1.DBUpgradeSpec{
+			Database: dbupgradev1alpha1.DatabaseSpec{
+				TLS: &dbupgradev1alpha1.DatabaseTLSSpec{UseRDSCA: true},
+			},
+			Runner: &dbupgradev1alpha1.RunnerSpec{NodeSelector: map[string]string{"pool": "batch"}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: migrationSecretName(dbUpgrade)},
+		Data: map[string][]byte{
+			engine.URLKey:            []byte("postgres://app:pass@db:5432/app"),
+			engine.SQLCheckConfigKey: []byte("[dbupgrade]\n"),
+		},
+	}
+	sqlChecks := []dbupgradev1alpha1.SQLCheck{
+		{Name: "orders", Query: "SELECT count(*) FROM orders", TimeoutSeconds: 30},
+		{Name: "legacy", Query: "SELECT count(*) FROM legacy_orders", TimeoutSeconds: 60},
+	}
+
+	name := sqlCheckJobName("dbupgrade-app-1234abcd", checkPhasePost)
+	if name != "dbupgrade-app-1234abcd-postcheck" {
+		t.Errorf("sqlCheckJobName() = %s", name)
+	}
+	job, err := sqlCheckJob(dbUpgrade, name, checkPhasePost, secret, sqlChecks)
+	if err != nil {
+		t.Fatalf("sqlCheckJob() error = %v", err)
+	}
+
+	if !isCheckJob(job) || !metav1.IsControlledBy(job, dbUpgrade) {
+		t.Errorf("labels = %v, owners = %v", job.Labels, job.OwnerReferences)
+	}
+	if *job.Spec.ActiveDeadlineSeconds != 2*60+120 {
+		t.Errorf("activeDeadlineSeconds = %d, expected 240", *job.Spec.ActiveDeadlineSeconds)
+	}
+	podSpec := job.Spec.Template.Spec
+	if podSpec.NodeSelector["pool"] != "batch" {
+		t.Errorf("nodeSelector = %v, expected the runner overrides", podSpec.NodeSelector)
+	}
+	if len(podSpec.Containers) != 2 || len(podSpec.Containers[1].VolumeMounts) != 2 {
+		t.Fatalf("containers = %+v, expected two with connection and TLS mounts", podSpec.Containers)
+	}
+	items := podSpec.Volumes[0].Secret.Items
+	if len(items) != 1 || items[0].Key != engine.SQLCheckConfigKey {
+		t.Errorf("connection volume items = %v, expected only %s", items, engine.SQLCheckConfigKey)
+	}
+
+	delete(secret.Data, engine.SQLCheckConfigKey)
+	if _, err := sqlCheckJob(dbUpgrade, name, checkPhasePost, secret, sqlChecks); err == nil {
+		t.Error("sqlCheckJob() expected error without the connection file")
+	}
+}
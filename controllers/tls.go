@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/env"
+)
+
+// RDSCABundlePath is the AWS RDS CA bundle shipped in the operator image,
+// used for database.tls.useRDSCA. Override via RDS_CA_BUNDLE env var.
+var RDSCABundlePath = env.GetOrDefault("RDS_CA_BUNDLE", "/etc/dbupgrade/rds-global-bundle.pem")
+
+const (
+	// databaseTLSVolume holds the TLS files projected from the operator Secret
+	databaseTLSVolume = "database-tls"
+
+	// DatabaseTLSMountPath is where the migrate container sees the TLS files
+	DatabaseTLSMountPath = "/etc/dbupgrade/tls"
+)
+
+// Keys of the TLS files in the operator-managed Secret; they are mounted
+// under DatabaseTLSMountPath with the same names
+const (
+	tlsCAKey         = "tls-ca.crt"
+	tlsClientCertKey = "tls-client.crt"
+	tlsClientKeyKey  = "tls-client.key"
+)
+
+// clientKeyFSGroup is the fsGroup set on pods mounting a client key when
+// spec.runner does not set one. Any group works: the kubelet gives the key
+// file this group and adds it to the containers' supplemental groups.
+const clientKeyFSGroup = int64(65534)
+
+// databaseTLSData reads the CA bundle and client certificate referenced by
+// database.tls so they can be copied into the operator-managed Secret
+func (r *DBUpgradeReconciler) databaseTLSData(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) (map[string][]byte, error) {
+	spec := dbUpgrade.Spec.Database.TLS
+	if spec == nil {
+		return nil, nil
+	}
+	data := map[string][]byte{}
+
+	switch {
+	case spec.CAConfigMapRef != nil:
+		ref := spec.CAConfigMapRef
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: dbUpgrade.Namespace}, cm); err != nil {
+			return nil, fmt.Errorf("failed to get CA ConfigMap: %w", err)
+		}
+		ca, ok := cm.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found in ConfigMap %q", ref.Key, ref.Name)
+		}
+		data[tlsCAKey] = []byte(ca)
+	case spec.CASecretRef != nil:
+		ref := spec.CASecretRef
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: dbUpgrade.Namespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get CA secret: %w", err)
+		}
+		ca, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found in secret %q", ref.Key, ref.Name)
+		}
+		data[tlsCAKey] = ca
+	case spec.UseRDSCA:
+		ca, err := os.ReadFile(RDSCABundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RDS CA bundle: %w", err)
+		}
+		data[tlsCAKey] = ca
+	}
+
+	if spec.ClientCertSecretRef != nil {
+		name := spec.ClientCertSecretRef.Name
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: dbUpgrade.Namespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get client certificate secret: %w", err)
+		}
+		for key, dest := range map[string]string{corev1.TLSCertKey: tlsClientCertKey, corev1.TLSPrivateKeyKey: tlsClientKeyKey} {
+			value, ok := secret.Data[key]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found in secret %q", key, name)
+			}
+			data[dest] = value
+		}
+	}
+	return data, nil
+}
+
+// tlsConnectionURL adds the URL parameters pointing the driver at the mounted
+// TLS files. The rest of the URL, including escaped credentials, is kept as is.
+func tlsConnectionURL(spec *dbupgradev1alpha1.DatabaseTLSSpec, connectionURL string) (string, error) {
+	if spec == nil {
+		return connectionURL, nil
+	}
+
+	u, err := url.Parse(connectionURL)
+	if err != nil {
+		// Do not wrap err: url.Error embeds the full URL including the password
+		return "", fmt.Errorf("database URL is not a valid URL")
+	}
+	query := u.Query()
+	clientCert := spec.ClientCertSecretRef != nil
+
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		mode := spec.Mode
+		if mode == "" {
+			mode = dbupgradev1alpha1.DatabaseTLSVerifyFull
+		}
+		query.Set("sslmode", string(mode))
+		query.Set("sslrootcert", path.Join(DatabaseTLSMountPath, tlsCAKey))
+		if clientCert {
+			query.Set("sslcert", path.Join(DatabaseTLSMountPath, tlsClientCertKey))
+			query.Set("sslkey", path.Join(DatabaseTLSMountPath, tlsClientKeyKey))
+		}
+	case "mysql", "maria", "mariadb":
+		// golang-migrate registers a TLS config under the tls name from x-tls-*
+		query.Set("tls", "custom")
+		query.Set("x-tls-ca", path.Join(DatabaseTLSMountPath, tlsCAKey))
+		if clientCert {
+			query.Set("x-tls-cert", path.Join(DatabaseTLSMountPath, tlsClientCertKey))
+			query.Set("x-tls-key", path.Join(DatabaseTLSMountPath, tlsClientKeyKey))
+		}
+	default:
+		return "", fmt.Errorf("database.tls is not supported for URL scheme %q", u.Scheme)
+	}
+
+	base, _, _ := strings.Cut(connectionURL, "?")
+	return base + "?" + query.Encode(), nil
+}
+
+// databaseTLSVolumeFor projects the TLS files of the operator Secret into a volume
+func databaseTLSVolumeFor(dbUpgrade *dbupgradev1alpha1.DBUpgrade, secretName string) (corev1.Volume, corev1.VolumeMount) {
+	certMode := int32(0444)
+	keyMode := int32(0440)
+	items := []corev1.KeyToPath{{Key: tlsCAKey, Path: tlsCAKey, Mode: &certMode}}
+	if dbUpgrade.Spec.Database.TLS.ClientCertSecretRef != nil {
+		items = append(items,
+			corev1.KeyToPath{Key: tlsClientCertKey, Path: tlsClientCertKey, Mode: &certMode},
+			// libpq rejects keys readable by others
+			corev1.KeyToPath{Key: tlsClientKeyKey, Path: tlsClientKeyKey, Mode: &keyMode})
+	}
+
+	volume := corev1.Volume{
+		Name: databaseTLSVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secretName, Items: items},
+		},
+	}
+	mount := corev1.VolumeMount{Name: databaseTLSVolume, MountPath: DatabaseTLSMountPath, ReadOnly: true}
+	return volume, mount
+}
+
+// ensureClientKeyReadable sets an fsGroup on a pod mounting the client key.
+// The key is mode 0440 and owned by root, so runners that run as non-root,
+// such as the Flyway and Liquibase images, can only read it through the group.
+func ensureClientKeyReadable(podSpec *corev1.PodSpec, dbUpgrade *dbupgradev1alpha1.DBUpgrade) {
+	if dbUpgrade.Spec.Database.TLS == nil || dbUpgrade.Spec.Database.TLS.ClientCertSecretRef == nil {
+		return
+	}
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if podSpec.SecurityContext.FSGroup == nil {
+		fsGroup := clientKeyFSGroup
+		podSpec.SecurityContext.FSGroup = &fsGroup
+	}
+}
@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/checks"
+	"github.com/subganapathy/automatic-db-upgrades/internal/engine"
+	"github.com/subganapathy/automatic-db-upgrades/internal/env"
+	"github.com/subganapathy/automatic-db-upgrades/internal/vault"
+)
+
+// VaultLeaseAnnotation records on the operator Secret the Vault lease backing
+// its credentials. It is removed once the lease is revoked.
+const VaultLeaseAnnotation = "dbupgrade.subbug.learning/vault-lease-id"
+
+// VaultLeaseJobAnnotation records on the operator Secret which Job the Vault
+// lease was issued for
+const VaultLeaseJobAnnotation = "dbupgrade.subbug.learning/vault-lease-job"
+
+// VaultLeaseFinalizer keeps a DBUpgrade with Vault credentials from being
+// deleted until its lease is revoked, so deleting it mid-run (or before its
+// Job exists) does not leave the database user alive until the lease's TTL
+const VaultLeaseFinalizer = "dbupgrade.subbug.learning/vault-lease"
+
+// Vault servers and tokens DBUpgrades may use
+var (
+	// VaultAudience is the audience of the ServiceAccount tokens presented to
+	// Vault, so they are not accepted by the Kubernetes API. The Kubernetes
+	// auth role must list it in its audience. Override via VAULT_AUDIENCE env var.
+	VaultAudience = env.GetOrDefault("VAULT_AUDIENCE", "vault")
+
+	// VaultAllowedHosts are the Vault servers DBUpgrades may log in to, as
+	// host names, IP addresses or *.domain wildcards. Set via the
+	// comma-separated VAULT_ALLOWED_HOSTS env var; when empty, Vault
+	// credentials cannot be used.
+	VaultAllowedHosts = hostsFromEnv("VAULT_ALLOWED_HOSTS")
+
+	// AllowInsecureVault allows http:// Vault addresses (dev servers only)
+	AllowInsecureVault = os.Getenv("ALLOW_INSECURE_VAULT") == "true"
+)
+
+// vaultLoginTokenSeconds is the lifetime of the ServiceAccount token presented
+// to Vault; it is only used for a single login
+const vaultLoginTokenSeconds = int64(600)
+
+// ensureVaultCredentials returns the connection URL and credential annotations
+// for the Job jobName. A lease is reused for as long as it belongs to that Job;
+// a new one is only requested before the Job is created, so credentials of a
+// running Job are never replaced.
+func (r *DBUpgradeReconciler) ensureVaultCredentials(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, secret *corev1.Secret, jobName string, job *batchv1.Job) ([]byte, map[string]string, error) {
+	logger := log.FromContext(ctx)
+
+	if r.VaultClient == nil {
+		return nil, nil, fmt.Errorf("Vault client not configured - Vault support is disabled")
+	}
+
+	if secret != nil && secret.Annotations[VaultLeaseJobAnnotation] == jobName {
+		// Before the Job is created the lease may have run out (e.g. while
+		// prechecks blocked); once it exists the Job owns the credentials,
+		// even after they were revoked when it finished
+		expiresAt, ok := credentialsExpiry(secret)
+		fresh := ok && secret.Annotations[VaultLeaseAnnotation] != "" && time.Until(expiresAt) > tokenRefreshMargin
+		if job != nil || fresh {
+			annotations := map[string]string{}
+			for _, key := range credentialAnnotations {
+				if value, ok := secret.Annotations[key]; ok {
+					annotations[key] = value
+				}
+			}
+			return secret.Data[engine.URLKey], annotations, nil
+		}
+	}
+
+	// A lease for a previous Job is no longer needed
+	if secret != nil {
+		if err := r.revokeVaultLease(ctx, dbUpgrade, secret); err != nil {
+			logger.Error(err, "Failed to revoke previous Vault lease")
+		}
+	}
+
+	cfg, err := r.vaultConfig(ctx, dbUpgrade)
+	if err != nil {
+		return nil, nil, err
+	}
+	spec := dbUpgrade.Spec.Database.Vault
+	lease, err := r.VaultClient.GenerateCredentials(ctx, cfg, spec.Mount, spec.Role)
+	if err != nil {
+		return nil, nil, err
+	}
+	expiresAt := time.Now().Add(lease.Duration)
+	logger.Info("Issued Vault database credentials",
+		"role", spec.Role,
+		"user", lease.Username,
+		"job", jobName,
+		"expiresAt", expiresAt.UTC().Format(time.RFC3339))
+
+	annotations := map[string]string{
+		VaultLeaseAnnotation:     lease.ID,
+		VaultLeaseJobAnnotation:  jobName,
+		TokenExpiresAtAnnotation: expiresAt.UTC().Format(time.RFC3339),
+	}
+	return []byte(vaultConnectionURL(dbUpgrade, lease.Username, lease.Password)), annotations, nil
+}
+
+// ensureVaultLeaseFinalizer adds VaultLeaseFinalizer before any lease is
+// requested for a DBUpgrade with Vault credentials
+func (r *DBUpgradeReconciler) ensureVaultLeaseFinalizer(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) error {
+	if dbUpgrade.Spec.Database.Vault == nil || controllerutil.ContainsFinalizer(dbUpgrade, VaultLeaseFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(dbUpgrade, VaultLeaseFinalizer)
+	if err := r.Update(ctx, dbUpgrade); err != nil {
+		return fmt.Errorf("failed to add Vault lease finalizer: %w", err)
+	}
+	return nil
+}
+
+// finalizeVaultLease revokes the lease recorded on the operator Secret of a
+// DBUpgrade being deleted and then removes VaultLeaseFinalizer. The finalizer
+// stays (and deletion waits) while revocation fails.
+func (r *DBUpgradeReconciler) finalizeVaultLease(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) error {
+	if !controllerutil.ContainsFinalizer(dbUpgrade, VaultLeaseFinalizer) {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: migrationSecretName(dbUpgrade), Namespace: dbUpgrade.Namespace}, secret)
+	switch {
+	case errors.IsNotFound(err):
+		// No credentials were issued
+	case err != nil:
+		return fmt.Errorf("failed to get migration secret: %w", err)
+	case dbUpgrade.Spec.Database.Vault == nil:
+		// Vault was removed from the spec, so there is no login to revoke with
+		if secret.Annotations[VaultLeaseAnnotation] != "" {
+			log.FromContext(ctx).Info("Vault no longer configured, leaving lease to expire",
+				"secret", secret.Name)
+		}
+	default:
+		if err := r.revokeVaultLease(ctx, dbUpgrade, secret); err != nil {
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(dbUpgrade, VaultLeaseFinalizer)
+	if err := r.Update(ctx, dbUpgrade); err != nil {
+		return fmt.Errorf("failed to remove Vault lease finalizer: %w", err)
+	}
+	return nil
+}
+
+// revokeVaultLease revokes the lease recorded on the operator Secret, if any,
+// and removes it from the Secret so it is only revoked once
+func (r *DBUpgradeReconciler) revokeVaultLease(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade, secret *corev1.Secret) error {
+	leaseID := secret.Annotations[VaultLeaseAnnotation]
+	if leaseID == "" {
+		return nil
+	}
+	if r.VaultClient == nil {
+		return fmt.Errorf("Vault client not configured - Vault support is disabled")
+	}
+
+	cfg, err := r.vaultConfig(ctx, dbUpgrade)
+	if err != nil {
+		return err
+	}
+	if err := r.VaultClient.RevokeLease(ctx, cfg, leaseID); err != nil {
+		return err
+	}
+
+	delete(secret.Annotations, VaultLeaseAnnotation)
+	if err := r.Update(ctx, secret); err != nil {
+		return fmt.Errorf("failed to update migration secret: %w", err)
+	}
+	log.FromContext(ctx).Info("Revoked Vault lease", "secret", secret.Name)
+	return nil
+}
+
+// vaultConfig builds the Vault login for the DBUpgrade. The token belongs to a
+// ServiceAccount in the DBUpgrade's namespace, so Vault roles bound to that
+// namespace keep tenants from using each other's database roles. It is only
+// minted for an allowed Vault server and for VaultAudience.
+func (r *DBUpgradeReconciler) vaultConfig(ctx context.Context, dbUpgrade *dbupgradev1alpha1.DBUpgrade) (vault.Config, error) {
+	spec := dbUpgrade.Spec.Database.Vault
+	if err := checkVaultAddress(spec.Address); err != nil {
+		return vault.Config{}, err
+	}
+
+	saName := spec.ServiceAccountName
+	if saName == "" {
+		saName = "default"
+	}
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: saName, Namespace: dbUpgrade.Namespace},
+	}
+	expirationSeconds := vaultLoginTokenSeconds
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         []string{VaultAudience},
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	if err := r.SubResource("token").Create(ctx, sa, tokenRequest); err != nil {
+		return vault.Config{}, fmt.Errorf("failed to request token for ServiceAccount %s: %w", saName, err)
+	}
+
+	return vault.Config{
+		Address:   spec.Address,
+		AuthMount: spec.AuthMount,
+		AuthRole:  spec.AuthRole,
+		JWT:       tokenRequest.Status.Token,
+	}, nil
+}
+
+// checkVaultAddress rejects Vault servers the operator does not allow, since
+// the ServiceAccount token and the issued credentials are sent to them
+func checkVaultAddress(address string) error {
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("invalid Vault address: %w", err)
+	}
+	if u.Scheme != "https" && !AllowInsecureVault {
+		return fmt.Errorf("Vault address %s must use https (ALLOW_INSECURE_VAULT is not set)", address)
+	}
+	if !checks.HostAllowed(u.Hostname(), VaultAllowedHosts) {
+		return fmt.Errorf("Vault server %s is not allowed; ask the operator admin to add it to VAULT_ALLOWED_HOSTS", u.Hostname())
+	}
+	return nil
+}
+
+// vaultConnectionURL builds the canonical connection URL for Vault-issued credentials
+func vaultConnectionURL(dbUpgrade *dbupgradev1alpha1.DBUpgrade, username, password string) string {
+	spec := dbUpgrade.Spec.Database.Vault
+	return databaseURL(dbUpgrade, spec.Host, spec.Port, spec.DBName, username, password, spec.Params)
+}
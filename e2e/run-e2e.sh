@@ -24,7 +24,6 @@ REGISTRY_PORT="5001"
 # Image names (using local registry)
 OPERATOR_IMG="localhost:${REGISTRY_PORT}/dbupgrade-operator:e2e"
 MIGRATIONS_IMG="localhost:${REGISTRY_PORT}/sample-migrations:e2e"
-CRANE_TAR_IMG="localhost:${REGISTRY_PORT}/crane-tar:e2e"
 
 log_info() {
     echo -e "${GREEN}[INFO]${NC} $1"
@@ -136,28 +135,12 @@ deploy_postgres() {
     log_info "PostgreSQL deployed successfully"
 }
 
-# Build and push custom crane-tar image (pinned version, minimal)
-build_crane_tar_image() {
-    log_info "Building crane-tar image (pinned crane version)..."
-
-    local INTERNAL_IMG="kind-registry:5000/crane-tar:e2e"
-    docker build --provenance=false -t "${INTERNAL_IMG}" "${PROJECT_ROOT}/images/crane-tar"
-
-    # Tag for localhost access (for pushing)
-    docker tag "${INTERNAL_IMG}" "${CRANE_TAR_IMG}"
-
-    log_info "Pushing crane-tar image to local registry..."
-    docker push "${CRANE_TAR_IMG}"
-
-    log_info "crane-tar image pushed successfully"
-}
-
 # Build and push sample migrations image to local registry
 build_migrations_image() {
     log_info "Building sample migrations image..."
 
     # Docker buildx may add provenance/attestation manifests which create multi-manifest images
-    # The operator handles this via --platform flag in crane export (dynamic arch detection)
+    # The fetcher picks the manifest for the node's platform from the index
     local INTERNAL_IMG="kind-registry:5000/sample-migrations:e2e"
     docker build -t "${INTERNAL_IMG}" "${SCRIPT_DIR}/sample-migrations"
 
@@ -331,7 +314,6 @@ main() {
     create_registry
     create_cluster
     deploy_postgres
-    build_crane_tar_image
     build_migrations_image
     deploy_operator
     create_test_resources
@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/subganapathy/automatic-db-upgrades/internal/metrics"
+)
+
+// credentialsExpiryWindow is how long before expiry cached credentials are
+// treated as expired. A token stops working when the session that signed it
+// expires, so the session must outlive every token minted from it.
+const credentialsExpiryWindow = RDSAuthTokenTTL
+
+// assumeRoleDuration is the requested session length. One hour is the
+// default maximum of every role, including chained role sessions.
+const assumeRoleDuration = time.Hour
+
+// credentialsKey identifies an assumed role session in the credentials cache
+type credentialsKey struct {
+	RoleArn    string
+	ExternalID string
+}
+
+// credentialsCache shares STS AssumeRole credentials across reconciles.
+// Without it every reconcile of every DBUpgrade calls STS, which is throttled
+// once hundreds of databases are managed.
+type credentialsCache struct {
+	mu      sync.Mutex
+	entries map[credentialsKey]aws.Credentials
+	// inflight collapses concurrent misses for the same key into one STS call
+	inflight singleflight.Group
+	// now is overridden in tests
+	now func() time.Time
+}
+
+func newCredentialsCache() *credentialsCache {
+	return &credentialsCache{
+		entries: map[credentialsKey]aws.Credentials{},
+		now:     time.Now,
+	}
+}
+
+// get returns cached credentials for key, calling retrieve on a miss.
+// Concurrent misses for the same key share a single retrieve.
+// Expired entries are evicted whenever the cache is written to.
+func (c *credentialsCache) get(ctx context.Context, key credentialsKey, retrieve func(context.Context) (aws.Credentials, error)) (aws.Credentials, error) {
+	if creds, ok := c.lookup(key); ok {
+		metrics.AWSCredentialsCacheHits.Inc()
+		return creds, nil
+	}
+	metrics.AWSCredentialsCacheMisses.Inc()
+
+	// Retrieve without holding the lock so a slow STS call for one role
+	// doesn't block reconciles of other databases
+	v, err, _ := c.inflight.Do(key.RoleArn+"\x00"+key.ExternalID, func() (interface{}, error) {
+		// A call that just finished may have filled the entry
+		if creds, ok := c.lookup(key); ok {
+			return creds, nil
+		}
+		creds, err := retrieve(ctx)
+		if err != nil {
+			metrics.AWSSTSErrors.Inc()
+			return nil, err
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for k, v := range c.entries {
+			if !c.valid(v) {
+				delete(c.entries, k)
+			}
+		}
+		c.entries[key] = creds
+		return creds, nil
+	})
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	return v.(aws.Credentials), nil
+}
+
+// lookup returns the cached credentials for key if they are still valid
+func (c *credentialsCache) lookup(key credentialsKey) (aws.Credentials, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	creds, ok := c.entries[key]
+	if !ok || !c.valid(creds) {
+		return aws.Credentials{}, false
+	}
+	return creds, true
+}
+
+// valid reports whether creds can still be used outside the expiry window
+func (c *credentialsCache) valid(creds aws.Credentials) bool {
+	if !creds.CanExpire {
+		return true
+	}
+	return creds.Expires.Sub(c.now()) > credentialsExpiryWindow
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// TestCredentialsCache tests that sessions are reused until close to expiry
+func TestCredentialsCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := newCredentialsCache()
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	retrieve := func(context.Context) (aws.Credentials, error) {
+		calls++
+		return aws.Credentials{AccessKeyID: "AKIA", CanExpire: true, Expires: now.Add(time.Hour)}, nil
+	}
+	key := credentialsKey{RoleArn: "arn:aws:iam::123456789012:role/db", ExternalID: "team-a/app"}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.get(context.Background(), key, retrieve); err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 STS call for repeated gets, got %d", calls)
+	}
+
+	// Another tenant assuming the same role gets its own session
+	other := credentialsKey{RoleArn: key.RoleArn, ExternalID: "team-b/app"}
+	if _, err := cache.get(context.Background(), other, retrieve); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a separate STS call per ExternalID, got %d calls", calls)
+	}
+
+	// Inside the expiry window the session is refreshed
+	now = now.Add(time.Hour - credentialsExpiryWindow)
+	if _, err := cache.get(context.Background(), key, retrieve); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected credentials close to expiry to be refreshed, got %d calls", calls)
+	}
+	if _, ok := cache.entries[other]; ok {
+		t.Errorf("expected expiring entry for %v to be evicted", other)
+	}
+}
+
+// TestCredentialsCacheConcurrentMisses tests that concurrent reconciles
+// missing the cache for the same role make a single STS call
+func TestCredentialsCacheConcurrentMisses(t *testing.T) {
+	cache := newCredentialsCache()
+	key := credentialsKey{RoleArn: "arn:aws:iam::123456789012:role/db", ExternalID: "team-a/app"}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	retrieve := func(context.Context) (aws.Credentials, error) {
+		calls.Add(1)
+		<-release
+		return aws.Credentials{AccessKeyID: "AKIA", CanExpire: true, Expires: time.Now().Add(time.Hour)}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.get(context.Background(), key, retrieve); err != nil {
+				t.Errorf("get() error = %v", err)
+			}
+		}()
+	}
+	// Let the goroutines pile up on the in-flight call before it returns
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 STS call for concurrent misses, got %d", n)
+	}
+}
+
+// TestCredentialsCacheError tests that failed STS calls are not cached
+func TestCredentialsCacheError(t *testing.T) {
+	cache := newCredentialsCache()
+	key := credentialsKey{RoleArn: "arn:aws:iam::123456789012:role/db"}
+
+	_, err := cache.get(context.Background(), key, func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, errors.New("throttled")
+	})
+	if err == nil {
+		t.Fatal("expected error from failed retrieve")
+	}
+	if _, ok := cache.entries[key]; ok {
+		t.Error("expected failed retrieve not to be cached")
+	}
+}
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+)
+
+// ECREndpointEnv overrides the ECR API endpoint, e.g. to point the operator
+// at a local stand-in. Same variable as the AWS SDKs use.
+const ECREndpointEnv = "AWS_ENDPOINT_URL_ECR"
+
+// ecrRegistryPattern matches private ECR registry hosts and captures the region
+var ecrRegistryPattern = regexp.MustCompile(`^\d{12}\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// ECRRegion returns the region of a private ECR registry host
+// (123456789012.dkr.ecr.us-east-1.amazonaws.com), or false for other hosts
+func ECRRegion(registry string) (string, bool) {
+	m := ecrRegistryPattern.FindStringSubmatch(registry)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// ECRAuthConfig identifies the role used to pull from ECR
+type ECRAuthConfig struct {
+	// Region is the registry's region
+	Region string
+	// RoleArn is the IAM role to assume for ecr:GetAuthorizationToken
+	RoleArn string
+	// ExternalID is passed to STS AssumeRole for tenant isolation
+	ExternalID string
+}
+
+// RegistryCredentials are docker login credentials for a registry
+type RegistryCredentials struct {
+	Username  string
+	Password  string
+	ExpiresAt time.Time
+}
+
+// GetECRAuthorizationToken mints docker credentials for ECR (valid for 12
+// hours). The role is assumed with ExternalID exactly as for RDS IAM tokens.
+func (m *ClientManager) GetECRAuthorizationToken(ctx context.Context, cfg ECRAuthConfig) (RegistryCredentials, error) {
+	awsCfg, err := m.tenantConfig(ctx, cfg.Region, cfg.RoleArn, cfg.ExternalID)
+	if err != nil {
+		return RegistryCredentials{}, err
+	}
+	if awsCfg.Credentials == nil {
+		return RegistryCredentials{}, fmt.Errorf("no AWS credentials available for ECR")
+	}
+
+	client := ecr.NewFromConfig(awsCfg, func(o *ecr.Options) {
+		if m.ecrEndpoint != "" {
+			o.BaseEndpoint = aws.String(m.ecrEndpoint)
+		}
+	})
+	out, err := client.GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return RegistryCredentials{}, fmt.Errorf("failed to get ECR authorization token: %w", err)
+	}
+	if len(out.AuthorizationData) == 0 {
+		return RegistryCredentials{}, fmt.Errorf("ECR returned no authorization data")
+	}
+
+	// The token is base64("AWS:<password>")
+	data := out.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(aws.ToString(data.AuthorizationToken))
+	if err != nil {
+		return RegistryCredentials{}, fmt.Errorf("failed to decode ECR authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return RegistryCredentials{}, fmt.Errorf("ECR authorization token is not in user:password form")
+	}
+	return RegistryCredentials{
+		Username:  username,
+		Password:  password,
+		ExpiresAt: aws.ToTime(data.ExpiresAt),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestECRRegion tests detection of private ECR registry hosts
+func TestECRRegion(t *testing.T) {
+	tests := []struct {
+		registry string
+		region   string
+		ok       bool
+	}{
+		{registry: "123456789012.dkr.ecr.us-east-1.amazonaws.com", region: "us-east-1", ok: true},
+		{registry: "123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn", region: "cn-north-1", ok: true},
+		{registry: "public.ecr.aws"},
+		{registry: "ghcr.io"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.registry, func(t *testing.T) {
+			region, ok := ECRRegion(tt.registry)
+			if region != tt.region || ok != tt.ok {
+				t.Errorf("ECRRegion() = %q, %v, expected %q, %v", region, ok, tt.region, tt.ok)
+			}
+		})
+	}
+}
+
+// TestGetECRAuthorizationToken tests minting registry credentials from a stand-in endpoint
+func TestGetECRAuthorizationToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Amz-Target"); got != "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken" {
+			t.Errorf("X-Amz-Target = %q", got)
+		}
+		if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "/eu-west-1/ecr/aws4_request") {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		token := base64.StdEncoding.EncodeToString([]byte("AWS:ecr-password"))
+		_, _ = w.Write([]byte(`{"authorizationData":[{"authorizationToken":"` + token + `","expiresAt":1.7e9}]}`))
+	}))
+	defer srv.Close()
+
+	m := newTestClientManager("")
+	m.ecrEndpoint = srv.URL
+	creds, err := m.GetECRAuthorizationToken(context.Background(), ECRAuthConfig{Region: "eu-west-1"})
+	if err != nil {
+		t.Fatalf("GetECRAuthorizationToken() error = %v", err)
+	}
+	if creds.Username != "AWS" || creds.Password != "ecr-password" || creds.ExpiresAt.Unix() != 1700000000 {
+		t.Errorf("GetECRAuthorizationToken() = %+v", creds)
+	}
+}
@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"net/url"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/subganapathy/automatic-db-upgrades/internal/dburl"
 )
 
 // ClientManager manages shared AWS clients with connection pooling.
@@ -22,6 +25,12 @@ type ClientManager struct {
 	baseConfig  aws.Config
 	mu          sync.RWMutex
 	initialized bool
+	// credsCache caches assumed role sessions by role ARN and ExternalID
+	credsCache *credentialsCache
+	// secretsManagerEndpoint overrides the regional Secrets Manager endpoint
+	secretsManagerEndpoint string
+	// ecrEndpoint overrides the regional ECR API endpoint
+	ecrEndpoint string
 }
 
 // NewClientManager creates a new AWS client manager with connection pooling.
@@ -37,6 +46,9 @@ func NewClientManager() *ClientManager {
 			},
 			Timeout: 30 * time.Second,
 		},
+		credsCache:             newCredentialsCache(),
+		secretsManagerEndpoint: os.Getenv(SecretsManagerEndpointEnv),
+		ecrEndpoint:            os.Getenv(ECREndpointEnv),
 	}
 }
 
@@ -62,8 +74,30 @@ func (m *ClientManager) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// RDSAuthTokenTTL is how long an RDS IAM auth token is accepted for
+const RDSAuthTokenTTL = 15 * time.Minute
+
+// Database engines supported for IAM authentication
+const (
+	EnginePostgres = "postgres"
+	EngineMySQL    = "mysql"
+	EngineMariaDB  = "mariadb"
+)
+
+// DefaultPort returns the default port of a database engine
+func DefaultPort(engine string) int32 {
+	switch engine {
+	case EngineMySQL, EngineMariaDB:
+		return 3306
+	default:
+		return 5432
+	}
+}
+
 // RDSAuthConfig holds the configuration for RDS IAM authentication
 type RDSAuthConfig struct {
+	// Engine is the database engine (postgres, mysql or mariadb)
+	Engine string
 	// Region is the AWS region (e.g., us-east-1)
 	Region string
 	// Host is the RDS endpoint (e.g., mydb.123456789012.us-east-1.rds.amazonaws.com)
@@ -89,58 +123,106 @@ type RDSAuthConfig struct {
 // This provides tenant isolation - the target role's trust policy
 // should require the specific ExternalID to prevent cross-tenant access.
 func (m *ClientManager) GenerateRDSAuthToken(ctx context.Context, cfg RDSAuthConfig) (string, error) {
+	awsCfg, err := m.tenantConfig(ctx, cfg.Region, cfg.RoleArn, cfg.ExternalID)
+	if err != nil {
+		return "", err
+	}
+
+	// Generate the authentication token
+	endpoint := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	token, err := auth.BuildAuthToken(ctx, endpoint, cfg.Region, cfg.Username, awsCfg.Credentials)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate RDS auth token: %w", err)
+	}
+
+	return token, nil
+}
+
+// tenantConfig returns a copy of the base config for region, with the
+// credentials of roleArn assumed with externalID if a role is given
+func (m *ClientManager) tenantConfig(ctx context.Context, region, roleArn, externalID string) (aws.Config, error) {
 	m.mu.RLock()
 	if !m.initialized {
 		m.mu.RUnlock()
-		return "", fmt.Errorf("AWS client manager not initialized - call Initialize() first")
+		return aws.Config{}, fmt.Errorf("AWS client manager not initialized - call Initialize() first")
 	}
 	// Copy base config to avoid mutation
 	awsCfg := m.baseConfig.Copy()
 	m.mu.RUnlock()
 
 	// Override region if specified
-	if cfg.Region != "" {
-		awsCfg.Region = cfg.Region
+	if region != "" {
+		awsCfg.Region = region
 	}
 
-	// If RoleArn is specified, assume the role with ExternalID
-	if cfg.RoleArn != "" {
+	// If RoleArn is specified, assume the role with ExternalID. The session
+	// is cached so reconciles don't call STS until it is close to expiry.
+	if roleArn != "" {
 		stsClient := sts.NewFromConfig(awsCfg)
-		creds := stscreds.NewAssumeRoleProvider(stsClient, cfg.RoleArn,
+		assumeRole := stscreds.NewAssumeRoleProvider(stsClient, roleArn,
 			func(o *stscreds.AssumeRoleOptions) {
 				// ExternalID provides tenant isolation.
 				// The target role's trust policy must require this exact ExternalID.
-				if cfg.ExternalID != "" {
-					o.ExternalID = aws.String(cfg.ExternalID)
+				if externalID != "" {
+					o.ExternalID = aws.String(externalID)
 				}
 				// Session name for CloudTrail auditing
 				o.RoleSessionName = "dbupgrade-operator"
+				o.Duration = assumeRoleDuration
 			},
 		)
-		awsCfg.Credentials = aws.NewCredentialsCache(creds)
+		key := credentialsKey{RoleArn: roleArn, ExternalID: externalID}
+		creds, err := m.credsCache.get(ctx, key, assumeRole.Retrieve)
+		if err != nil {
+			return aws.Config{}, fmt.Errorf("failed to assume role %s: %w", roleArn, err)
+		}
+		awsCfg.Credentials = credentials.StaticCredentialsProvider{Value: creds}
 	}
 
-	// Generate the authentication token
-	endpoint := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	token, err := auth.BuildAuthToken(ctx, endpoint, cfg.Region, cfg.Username, awsCfg.Credentials)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate RDS auth token: %w", err)
-	}
+	return awsCfg, nil
+}
 
-	return token, nil
+// BuildConnectionURL builds the connection URL for cfg.Engine using IAM auth.
+// token may also be a password read from Secrets Manager.
+func BuildConnectionURL(cfg RDSAuthConfig, token string) string {
+	switch cfg.Engine {
+	case EngineMySQL:
+		return BuildMySQLConnectionURL(cfg, token)
+	case EngineMariaDB:
+		return BuildMariaDBConnectionURL(cfg, token)
+	default:
+		return BuildPostgresConnectionURL(cfg, token)
+	}
 }
 
 // BuildPostgresConnectionURL builds a PostgreSQL connection URL using IAM auth
 func BuildPostgresConnectionURL(cfg RDSAuthConfig, token string) string {
-	// URL-encode the token since it contains special characters
-	encodedToken := url.QueryEscape(token)
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=require",
-		cfg.Username, encodedToken, cfg.Host, cfg.Port, cfg.DBName)
+	return connectionURL("postgres", cfg, token, "sslmode=require")
 }
 
-// BuildMySQLConnectionURL builds a MySQL connection URL using IAM auth
+// BuildMySQLConnectionURL builds a MySQL connection URL using IAM auth.
+// RDS verifies IAM tokens through the mysql_clear_password plugin, which
+// go-sql-driver only uses over TLS with allowCleartextPasswords set.
 func BuildMySQLConnectionURL(cfg RDSAuthConfig, token string) string {
-	encodedToken := url.QueryEscape(token)
-	return fmt.Sprintf("mysql://%s:%s@%s:%d/%s?tls=true",
-		cfg.Username, encodedToken, cfg.Host, cfg.Port, cfg.DBName)
+	return connectionURL("mysql", cfg, token, "tls=true&allowCleartextPasswords=true")
+}
+
+// BuildMariaDBConnectionURL builds a MariaDB connection URL using IAM auth.
+// Same as MySQL, with the maria:// scheme so engines pick their MariaDB dialect.
+func BuildMariaDBConnectionURL(cfg RDSAuthConfig, token string) string {
+	return connectionURL("maria", cfg, token, "tls=true&allowCleartextPasswords=true")
+}
+
+// connectionURL renders the URL; the token contains special characters and
+// is escaped by dburl.URL
+func connectionURL(scheme string, cfg RDSAuthConfig, token, rawQuery string) string {
+	return dburl.URL{
+		Scheme:   scheme,
+		Username: cfg.Username,
+		Password: token,
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Database: cfg.DBName,
+		RawQuery: rawQuery,
+	}.String()
 }
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// SecretsManagerEndpointEnv overrides the Secrets Manager endpoint, e.g. to
+// point the operator at a local stand-in. Same variable as the AWS SDKs use.
+const SecretsManagerEndpointEnv = "AWS_ENDPOINT_URL_SECRETS_MANAGER"
+
+// SecretsManagerConfig identifies a database secret in AWS Secrets Manager
+type SecretsManagerConfig struct {
+	// SecretArn is the ARN of the secret; its region is used for the request
+	SecretArn string
+	// RoleArn is the IAM role to assume for reading the secret
+	RoleArn string
+	// ExternalID is passed to STS AssumeRole for tenant isolation
+	ExternalID string
+}
+
+// DatabaseCredentials holds the username and password stored in a secret
+type DatabaseCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// GetDatabaseCredentials reads a database secret from AWS Secrets Manager.
+// The secret must be a JSON object with a password (and optionally a
+// username), which is the format of RDS-managed and rotated secrets.
+//
+// The role is assumed with ExternalID exactly as for RDS IAM tokens.
+func (m *ClientManager) GetDatabaseCredentials(ctx context.Context, cfg SecretsManagerConfig) (DatabaseCredentials, error) {
+	secretArn, err := arn.Parse(cfg.SecretArn)
+	if err != nil {
+		return DatabaseCredentials{}, fmt.Errorf("invalid secret ARN %q: %w", cfg.SecretArn, err)
+	}
+
+	awsCfg, err := m.tenantConfig(ctx, secretArn.Region, cfg.RoleArn, cfg.ExternalID)
+	if err != nil {
+		return DatabaseCredentials{}, err
+	}
+	if awsCfg.Credentials == nil {
+		return DatabaseCredentials{}, fmt.Errorf("no AWS credentials available to read secret %s", cfg.SecretArn)
+	}
+
+	secretString, err := m.getSecretValue(ctx, awsCfg, cfg.SecretArn)
+	if err != nil {
+		return DatabaseCredentials{}, err
+	}
+
+	var dbCreds DatabaseCredentials
+	if err := json.Unmarshal([]byte(secretString), &dbCreds); err != nil {
+		return DatabaseCredentials{}, fmt.Errorf("secret %s is not a JSON object with username and password", cfg.SecretArn)
+	}
+	if dbCreds.Password == "" {
+		return DatabaseCredentials{}, fmt.Errorf("secret %s has no password", cfg.SecretArn)
+	}
+	return dbCreds, nil
+}
+
+// getSecretValue calls the Secrets Manager GetSecretValue API with awsCfg
+// and returns the SecretString
+func (m *ClientManager) getSecretValue(ctx context.Context, awsCfg aws.Config, secretID string) (string, error) {
+	client := secretsmanager.NewFromConfig(awsCfg, func(o *secretsmanager.Options) {
+		if m.secretsManagerEndpoint != "" {
+			o.BaseEndpoint = aws.String(m.secretsManagerEndpoint)
+		}
+	})
+	out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", secretID, err)
+	}
+	if aws.ToString(out.SecretString) == "" {
+		return "", fmt.Errorf("secret %s has no SecretString (binary secrets are not supported)", secretID)
+	}
+	return aws.ToString(out.SecretString), nil
+}
@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const testSecretArn = "arn:aws:secretsmanager:us-west-2:123456789012:secret:rds!db-1234-AbCdEf"
+
+// newTestClientManager returns a ClientManager with static credentials that
+// sends Secrets Manager requests to endpoint
+func newTestClientManager(endpoint string) *ClientManager {
+	m := NewClientManager()
+	m.baseConfig = aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", "")}
+	m.initialized = true
+	m.secretsManagerEndpoint = endpoint
+	return m
+}
+
+// TestGetDatabaseCredentials tests reading an RDS-managed secret from a stand-in endpoint
+func TestGetDatabaseCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Amz-Target"); got != "secretsmanager.GetSecretValue" {
+			t.Errorf("X-Amz-Target = %q, expected secretsmanager.GetSecretValue", got)
+		}
+		// Requests are signed for the secret's region, not the base config's
+		if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "/us-west-2/secretsmanager/aws4_request") {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var in map[string]string
+		if err := json.Unmarshal(body, &in); err != nil || in["SecretId"] != testSecretArn {
+			t.Errorf("unexpected request body %s", body)
+		}
+		_, _ = w.Write([]byte(`{"ARN":"` + testSecretArn + `","SecretString":"{\"username\":\"postgres\",\"password\":\"p@ss/word\"}"}`))
+	}))
+	defer srv.Close()
+
+	m := newTestClientManager(srv.URL)
+	creds, err := m.GetDatabaseCredentials(context.Background(), SecretsManagerConfig{SecretArn: testSecretArn})
+	if err != nil {
+		t.Fatalf("GetDatabaseCredentials() error = %v", err)
+	}
+	if creds.Username != "postgres" || creds.Password != "p@ss/word" {
+		t.Errorf("GetDatabaseCredentials() = %+v", creds)
+	}
+}
+
+// TestGetDatabaseCredentialsErrors tests that API and format errors are reported
+func TestGetDatabaseCredentialsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		expected string
+	}{
+		{
+			name:     "access denied",
+			status:   http.StatusBadRequest,
+			response: `{"__type":"AccessDeniedException","message":"not authorized"}`,
+			expected: "AccessDeniedException: not authorized",
+		},
+		{
+			name:     "plain text secret",
+			status:   http.StatusOK,
+			response: `{"SecretString":"hunter2"}`,
+			expected: "not a JSON object",
+		},
+		{
+			name:     "secret without password",
+			status:   http.StatusOK,
+			response: `{"SecretString":"{\"username\":\"postgres\"}"}`,
+			expected: "has no password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			m := newTestClientManager(srv.URL)
+			_, err := m.GetDatabaseCredentials(context.Background(), SecretsManagerConfig{SecretArn: testSecretArn})
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("GetDatabaseCredentials() error = %v, expected to contain %q", err, tt.expected)
+			}
+		})
+	}
+}
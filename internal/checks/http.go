@@ -0,0 +1,298 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// maxHTTPResponseBytes caps how much of a response body is read for jsonPath
+const maxHTTPResponseBytes = 1 << 20
+
+// defaultHTTPTimeoutSeconds matches the CRD default
+const defaultHTTPTimeoutSeconds = 10
+
+// HTTPCheckResult contains the result of HTTP checks
+type HTTPCheckResult struct {
+	Passed  bool
+	Message string
+}
+
+// NewHTTPClient creates the pooled client for HTTP checks. Timeouts are set
+// per check.
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// CheckHTTP calls each endpoint in order and stops at the first failure.
+// Unreachable endpoints and unexpected responses fail the check; only
+// configuration errors, such as a missing header Secret or a URL whose host is
+// not in allowedHosts, are returned as errors. Redirects are not followed.
+func CheckHTTP(ctx context.Context, c client.Client, httpClient *http.Client, allowedHosts []string, namespace string, checks []dbupgradev1alpha1.HTTPCheck) (*HTTPCheckResult, error) {
+	// A redirect could lead an allowed host to any other
+	noRedirects := *httpClient
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	for _, check := range checks {
+		result, err := checkSingleHTTP(ctx, c, &noRedirects, allowedHosts, namespace, check)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check http %s: %w", check.Name, err)
+		}
+		if !result.Passed {
+			return result, nil
+		}
+	}
+
+	return &HTTPCheckResult{
+		Passed:  true,
+		Message: fmt.Sprintf("All %d HTTP check(s) passed", len(checks)),
+	}, nil
+}
+
+func checkSingleHTTP(ctx context.Context, c client.Client, httpClient *http.Client, allowedHosts []string, namespace string, check dbupgradev1alpha1.HTTPCheck) (*HTTPCheckResult, error) {
+	logger := log.FromContext(ctx)
+
+	req, err := newHTTPCheckRequest(ctx, c, allowedHosts, namespace, check)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := check.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeoutSeconds
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	resp, err := httpClient.Do(req.WithContext(reqCtx))
+	if err != nil {
+		return &HTTPCheckResult{
+			Passed:  false,
+			Message: fmt.Sprintf("HTTP check %s: %s %s failed: %v", check.Name, req.Method, req.URL.Redacted(), err),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	if !statusExpected(resp.StatusCode, check.ExpectedStatusCodes) {
+		return &HTTPCheckResult{
+			Passed:  false,
+			Message: fmt.Sprintf("HTTP check %s: %s %s returned %d, expected %s", check.Name, req.Method, req.URL.Redacted(), resp.StatusCode, expectedStatusString(check.ExpectedStatusCodes)),
+		}, nil
+	}
+
+	if check.JSONPath == "" || check.Threshold == nil {
+		logger.Info("HTTP check result", "check", check.Name, "status", resp.StatusCode, "passed", true)
+		return &HTTPCheckResult{
+			Passed:  true,
+			Message: fmt.Sprintf("HTTP check %s returned %d", check.Name, resp.StatusCode),
+		}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBytes))
+	if err != nil {
+		return &HTTPCheckResult{
+			Passed:  false,
+			Message: fmt.Sprintf("HTTP check %s: failed to read response: %v", check.Name, err),
+		}, nil
+	}
+	value, err := jsonPathValue(check.JSONPath, body)
+	if err != nil {
+		return &HTTPCheckResult{
+			Passed:  false,
+			Message: fmt.Sprintf("HTTP check %s: %v", check.Name, err),
+		}, nil
+	}
+
+	thresholdValue := check.Threshold.Value.AsApproximateFloat64()
+	passed := compareThreshold(value, thresholdValue, check.Threshold.Operator)
+
+	logger.Info("HTTP check result",
+		"check", check.Name,
+		"status", resp.StatusCode,
+		"value", value,
+		"threshold", thresholdValue,
+		"operator", check.Threshold.Operator,
+		"passed", passed)
+
+	if !passed {
+		return &HTTPCheckResult{
+			Passed:  false,
+			Message: fmt.Sprintf("HTTP check %s value %.4f does not satisfy %s %.4f", check.Name, value, check.Threshold.Operator, thresholdValue),
+		}, nil
+	}
+	return &HTTPCheckResult{
+		Passed:  true,
+		Message: fmt.Sprintf("HTTP check %s value %.4f satisfies %s %.4f", check.Name, value, check.Threshold.Operator, thresholdValue),
+	}, nil
+}
+
+// newHTTPCheckRequest builds the request, resolving a Service reference to its
+// cluster DNS name and header values from Secrets. Services are in the
+// DBUpgrade's namespace; absolute URLs must be on one of allowedHosts, so
+// DBUpgrades cannot make the operator call cloud metadata or other
+// namespaces' endpoints.
+func newHTTPCheckRequest(ctx context.Context, c client.Client, allowedHosts []string, namespace string, check dbupgradev1alpha1.HTTPCheck) (*http.Request, error) {
+	target := check.URL
+	if svc := check.Service; svc != nil {
+		scheme, path := svc.Scheme, svc.Path
+		if scheme == "" {
+			scheme = "http"
+		}
+		if path == "" {
+			path = "/"
+		}
+		target = fmt.Sprintf("%s://%s.%s.svc:%d%s", scheme, svc.Name, namespace, svc.Port, path)
+	} else if target != "" {
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url: %w", err)
+		}
+		if !HostAllowed(u.Hostname(), allowedHosts) {
+			return nil, fmt.Errorf("host %s is not allowed for HTTP checks; use a service reference, or ask the operator admin to add it to HTTP_CHECK_ALLOWED_HOSTS", u.Hostname())
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("one of url and service is required")
+	}
+
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if check.Body != "" {
+		body = strings.NewReader(check.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	for _, h := range check.Headers {
+		value := h.Value
+		if ref := h.ValueSecretRef; ref != nil {
+			data, err := secretKey(ctx, c, namespace, ref.Name, ref.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get header %s: %w", h.Name, err)
+			}
+			value = strings.TrimSpace(string(data))
+		}
+		if strings.EqualFold(h.Name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Add(h.Name, value)
+	}
+	return req, nil
+}
+
+// HostAllowed reports whether host matches one of allowed: a host name or IP
+// address, or *.domain for any host below domain
+func HostAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// statusExpected reports whether the status is listed, or is 2xx by default
+func statusExpected(status int, expected []int32) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, code := range expected {
+		if int(code) == status {
+			return true
+		}
+	}
+	return false
+}
+
+func expectedStatusString(expected []int32) string {
+	if len(expected) == 0 {
+		return "2xx"
+	}
+	codes := make([]string, len(expected))
+	for i, code := range expected {
+		codes[i] = strconv.Itoa(int(code))
+	}
+	return strings.Join(codes, " or ")
+}
+
+// jsonPathValue selects a single value from a JSON document and converts it to
+// a number: numbers and numeric strings as is, booleans as 1 or 0
+func jsonPathValue(expr string, body []byte) (float64, error) {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return 0, fmt.Errorf("response is not JSON: %w", err)
+	}
+
+	jp := jsonpath.New("check")
+	if err := jp.Parse(expr); err != nil {
+		return 0, fmt.Errorf("invalid jsonPath %s: %w", expr, err)
+	}
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return 0, fmt.Errorf("jsonPath %s: %w", expr, err)
+	}
+	var values []interface{}
+	for _, r := range results {
+		for _, v := range r {
+			values = append(values, v.Interface())
+		}
+	}
+	if len(values) != 1 {
+		return 0, fmt.Errorf("jsonPath %s matched %d values, expected 1", expr, len(values))
+	}
+
+	switch v := values[0].(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("jsonPath %s value %q is not a number", expr, v)
+		}
+		return f, nil
+	case nil:
+		return 0, fmt.Errorf("jsonPath %s value is null", expr)
+	}
+	return 0, fmt.Errorf("jsonPath %s value is a %T, expected a number, numeric string or boolean", expr, values[0])
+}
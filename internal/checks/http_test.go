@@ -0,0 +1,165 @@
+package checks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// TestCheckHTTP tests status codes, JSONPath values and unreachable endpoints
+func TestCheckHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ready-for-migration":
+			if r.Header.Get("X-Token") != "abc" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"ready":true,"migration":{"pending":"3"},"pods":[{"v":1},{"v":2}]}`))
+		case "/draining":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/redirect":
+			http.Redirect(w, r, "/ready-for-migration", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	threshold := func(op dbupgradev1alpha1.ThresholdOperator, value string) *dbupgradev1alpha1.ThresholdSpec {
+		return &dbupgradev1alpha1.ThresholdSpec{Operator: op, Value: resource.MustParse(value)}
+	}
+	token := []dbupgradev1alpha1.HTTPHeader{{Name: "X-Token", Value: "abc"}}
+
+	tests := []struct {
+		name    string
+		check   dbupgradev1alpha1.HTTPCheck
+		passed  bool
+		message string
+	}{
+		{
+			name:   "2xx by default",
+			check:  dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/ready-for-migration", Headers: token},
+			passed: true,
+		},
+		{
+			name:    "missing header",
+			check:   dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/ready-for-migration"},
+			message: "returned 403, expected 2xx",
+		},
+		{
+			name:   "expected status code",
+			check:  dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/draining", ExpectedStatusCodes: []int32{200, 503}},
+			passed: true,
+		},
+		{
+			name:   "boolean value",
+			check:  dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/ready-for-migration", Headers: token, JSONPath: "{.ready}", Threshold: threshold(dbupgradev1alpha1.ThresholdOperatorGTE, "1")},
+			passed: true,
+		},
+		{
+			name:    "numeric string over threshold",
+			check:   dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/ready-for-migration", Headers: token, JSONPath: "{.migration.pending}", Threshold: threshold(dbupgradev1alpha1.ThresholdOperatorLTE, "0")},
+			message: "value 3.0000 does not satisfy <= 0.0000",
+		},
+		{
+			name:    "several values",
+			check:   dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/ready-for-migration", Headers: token, JSONPath: "{.pods[*].v}", Threshold: threshold(dbupgradev1alpha1.ThresholdOperatorLTE, "0")},
+			message: "matched 2 values, expected 1",
+		},
+		{
+			name:    "missing key",
+			check:   dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/ready-for-migration", Headers: token, JSONPath: "{.drained}", Threshold: threshold(dbupgradev1alpha1.ThresholdOperatorLTE, "0")},
+			message: "drained is not found",
+		},
+		{
+			name:    "redirect not followed",
+			check:   dbupgradev1alpha1.HTTPCheck{URL: srv.URL + "/redirect", Headers: token},
+			message: "returned 302, expected 2xx",
+		},
+		{
+			name:    "unreachable",
+			check:   dbupgradev1alpha1.HTTPCheck{URL: "http://127.0.0.1:1/ready", TimeoutSeconds: 1},
+			message: "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check.Name = "app"
+			result, err := CheckHTTP(context.Background(), nil, http.DefaultClient, []string{"127.0.0.1"}, "default", []dbupgradev1alpha1.HTTPCheck{tt.check})
+			if err != nil {
+				t.Fatalf("CheckHTTP() error = %v", err)
+			}
+			if result.Passed != tt.passed || !strings.Contains(result.Message, tt.message) {
+				t.Errorf("CheckHTTP() = %+v, expected passed=%v message containing %q", result, tt.passed, tt.message)
+			}
+		})
+	}
+}
+
+// TestNewHTTPCheckRequest tests the Service URL and the Host header
+func TestNewHTTPCheckRequest(t *testing.T) {
+	check := dbupgradev1alpha1.HTTPCheck{
+		Name:    "app",
+		Service: &dbupgradev1alpha1.HTTPServiceRef{Name: "app", Port: 8080, Path: "/flags?name=orders-v2"},
+		Method:  http.MethodPost,
+		Body:    `{"dryRun":true}`,
+		Headers: []dbupgradev1alpha1.HTTPHeader{{Name: "Host", Value: "app.example.com"}},
+	}
+
+	req, err := newHTTPCheckRequest(context.Background(), nil, nil, "payments", check)
+	if err != nil {
+		t.Fatalf("newHTTPCheckRequest() error = %v", err)
+	}
+	if got := req.URL.String(); got != "http://app.payments.svc:8080/flags?name=orders-v2" {
+		t.Errorf("URL = %s", got)
+	}
+	if req.Method != http.MethodPost || req.Host != "app.example.com" || req.Header.Get("Host") != "" {
+		t.Errorf("method = %s, host = %s, headers = %v", req.Method, req.Host, req.Header)
+	}
+}
+
+// TestCheckHTTPHostNotAllowed tests that absolute URLs outside the allowed
+// hosts are refused before any request is made
+func TestCheckHTTPHostNotAllowed(t *testing.T) {
+	check := dbupgradev1alpha1.HTTPCheck{Name: "metadata", URL: "http://169.254.169.254/latest/meta-data/"}
+	_, err := CheckHTTP(context.Background(), nil, http.DefaultClient, []string{"*.example.com"}, "default", []dbupgradev1alpha1.HTTPCheck{check})
+	if err == nil || !strings.Contains(err.Error(), "host 169.254.169.254 is not allowed") {
+		t.Errorf("CheckHTTP() error = %v, expected host not allowed", err)
+	}
+}
+
+// TestHostAllowed tests exact and wildcard host patterns
+func TestHostAllowed(t *testing.T) {
+	allowed := []string{"flags.internal", "*.Example.com", "10.0.0.5"}
+	tests := []struct {
+		host     string
+		expected bool
+	}{
+		{"flags.internal", true},
+		{"FLAGS.internal.", true},
+		{"api.example.com", true},
+		{"a.b.example.com", true},
+		{"example.com", false},
+		{"evilexample.com", false},
+		{"10.0.0.5", true},
+		{"169.254.169.254", false},
+		{"kubernetes.default.svc", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := HostAllowed(tt.host, allowed); got != tt.expected {
+			t.Errorf("HostAllowed(%q) = %v, expected %v", tt.host, got, tt.expected)
+		}
+	}
+	if HostAllowed("flags.internal", nil) {
+		t.Error("HostAllowed() with no allowed hosts = true")
+	}
+}
@@ -13,9 +13,11 @@ import (
 	"k8s.io/client-go/restmapper"
 	custommetricsclient "k8s.io/metrics/pkg/client/custom_metrics"
 	externalmetricsclient "k8s.io/metrics/pkg/client/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/prometheus"
 )
 
 // MetricCheckResult contains the result of a metric check
@@ -34,10 +36,17 @@ type MetricCheckResult struct {
 type MetricsChecker struct {
 	customMetricsClient   custommetricsclient.CustomMetricsClient
 	externalMetricsClient externalmetricsclient.ExternalMetricsClient
+	// client reads the Secrets referenced by Prometheus queries
+	client           client.Client
+	prometheusClient *prometheus.Client
+	// prometheusHosts are the Prometheus servers queries may be sent to
+	prometheusHosts []string
 }
 
-// NewMetricsChecker creates a new MetricsChecker from a rest.Config
-func NewMetricsChecker(config *rest.Config) (*MetricsChecker, error) {
+// NewMetricsChecker creates a new MetricsChecker from a rest.Config. The
+// Prometheus client may be nil if no check uses source=Prometheus; Prometheus
+// servers must be on one of prometheusHosts (see HostAllowed).
+func NewMetricsChecker(config *rest.Config, c client.Client, promClient *prometheus.Client, prometheusHosts []string) (*MetricsChecker, error) {
 	// Create discovery client to get available APIs
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
 	if err != nil {
@@ -66,6 +75,9 @@ func NewMetricsChecker(config *rest.Config) (*MetricsChecker, error) {
 	return &MetricsChecker{
 		customMetricsClient:   customClient,
 		externalMetricsClient: externalClient,
+		client:                c,
+		prometheusClient:      promClient,
+		prometheusHosts:       prometheusHosts,
 	}, nil
 }
 
@@ -101,6 +113,8 @@ func (m *MetricsChecker) checkSingleMetric(ctx context.Context, namespace string
 		values, err = m.getCustomMetricValues(ctx, namespace, check)
 	case dbupgradev1alpha1.MetricSourceExternal:
 		values, err = m.getExternalMetricValues(ctx, namespace, check)
+	case dbupgradev1alpha1.MetricSourcePrometheus:
+		values, err = m.getPrometheusValues(ctx, namespace, check)
 	default:
 		return nil, fmt.Errorf("unsupported metric source: %s", check.Source)
 	}
@@ -108,11 +122,12 @@ func (m *MetricsChecker) checkSingleMetric(ctx context.Context, namespace string
 	if err != nil {
 		return nil, err
 	}
+	metric := metricLabel(check)
 
 	if len(values) == 0 {
 		return &MetricCheckResult{
 			Passed:  false,
-			Message: fmt.Sprintf("No metric values found for %s", check.MetricName),
+			Message: fmt.Sprintf("No metric values found for %s", metric),
 		}, nil
 	}
 
@@ -125,7 +140,7 @@ func (m *MetricsChecker) checkSingleMetric(ctx context.Context, namespace string
 
 	logger.Info("Metric check result",
 		"check", check.Name,
-		"metric", check.MetricName,
+		"metric", metric,
 		"values", values,
 		"reduced", reducedValue,
 		"threshold", thresholdValue,
@@ -135,7 +150,7 @@ func (m *MetricsChecker) checkSingleMetric(ctx context.Context, namespace string
 	if !passed {
 		return &MetricCheckResult{
 			Passed:         false,
-			Message:        fmt.Sprintf("Metric %s value %.4f does not satisfy %s %.4f", check.MetricName, reducedValue, check.Threshold.Operator, thresholdValue),
+			Message:        fmt.Sprintf("Metric %s value %.4f does not satisfy %s %.4f", metric, reducedValue, check.Threshold.Operator, thresholdValue),
 			Values:         values,
 			ReducedValue:   reducedValue,
 			ThresholdValue: thresholdValue,
@@ -144,15 +159,27 @@ func (m *MetricsChecker) checkSingleMetric(ctx context.Context, namespace string
 
 	return &MetricCheckResult{
 		Passed:         true,
-		Message:        fmt.Sprintf("Metric %s value %.4f satisfies %s %.4f", check.MetricName, reducedValue, check.Threshold.Operator, thresholdValue),
+		Message:        fmt.Sprintf("Metric %s value %.4f satisfies %s %.4f", metric, reducedValue, check.Threshold.Operator, thresholdValue),
 		Values:         values,
 		ReducedValue:   reducedValue,
 		ThresholdValue: thresholdValue,
 	}, nil
 }
 
+// metricLabel names the metric in messages: the metric name, or the check
+// name for a PromQL query
+func metricLabel(check dbupgradev1alpha1.MetricCheck) string {
+	if check.MetricName != "" {
+		return check.MetricName
+	}
+	return check.Name
+}
+
 func (m *MetricsChecker) getCustomMetricValues(ctx context.Context, namespace string, check dbupgradev1alpha1.MetricCheck) ([]float64, error) {
 	var values []float64
+	if check.Target == nil {
+		return nil, fmt.Errorf("target configuration required for Custom source")
+	}
 
 	switch check.Target.Type {
 	case dbupgradev1alpha1.MetricTargetTypePods:
@@ -212,7 +239,7 @@ func (m *MetricsChecker) getExternalMetricValues(ctx context.Context, namespace
 	var selector labels.Selector
 	var err error
 
-	if check.Target.External != nil && check.Target.External.Selector != nil {
+	if check.Target != nil && check.Target.External != nil && check.Target.External.Selector != nil {
 		selector, err = metav1.LabelSelectorAsSelector(check.Target.External.Selector)
 		if err != nil {
 			return nil, fmt.Errorf("invalid external metric selector: %w", err)
@@ -0,0 +1,93 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/prometheus"
+)
+
+func (m *MetricsChecker) getPrometheusValues(ctx context.Context, namespace string, check dbupgradev1alpha1.MetricCheck) ([]float64, error) {
+	if check.Prometheus == nil {
+		return nil, fmt.Errorf("prometheus configuration required for Prometheus source")
+	}
+	if m.prometheusClient == nil {
+		return nil, fmt.Errorf("prometheus client not configured")
+	}
+	// The operator sends the query, and any bearer token, to this server
+	u, err := url.Parse(check.Prometheus.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prometheus url: %w", err)
+	}
+	if !HostAllowed(u.Hostname(), m.prometheusHosts) {
+		return nil, fmt.Errorf("prometheus host %s is not allowed; ask the operator admin to add it to PROMETHEUS_ALLOWED_HOSTS", u.Hostname())
+	}
+
+	cfg, err := prometheusConfig(ctx, m.client, namespace, check.Prometheus)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := m.prometheusClient.Query(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	return values, nil
+}
+
+// prometheusConfig resolves the bearer token and TLS material from the
+// DBUpgrade's namespace
+func prometheusConfig(ctx context.Context, c client.Client, namespace string, spec *dbupgradev1alpha1.PrometheusQuery) (prometheus.Config, error) {
+	cfg := prometheus.Config{URL: spec.URL, Query: spec.Query}
+
+	if ref := spec.BearerTokenSecretRef; ref != nil {
+		token, err := secretKey(ctx, c, namespace, ref.Name, ref.Key)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to get bearer token: %w", err)
+		}
+		cfg.BearerToken = strings.TrimSpace(string(token))
+	}
+
+	if spec.TLS == nil {
+		return cfg, nil
+	}
+	cfg.TLS = &prometheus.TLSConfig{InsecureSkipVerify: spec.TLS.InsecureSkipVerify}
+	if ref := spec.TLS.CASecretRef; ref != nil {
+		ca, err := secretKey(ctx, c, namespace, ref.Name, ref.Key)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to get CA bundle: %w", err)
+		}
+		cfg.TLS.CA = ca
+	}
+	if ref := spec.TLS.ClientCertSecretRef; ref != nil {
+		cert, err := secretKey(ctx, c, namespace, ref.Name, corev1.TLSCertKey)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to get client certificate: %w", err)
+		}
+		key, err := secretKey(ctx, c, namespace, ref.Name, corev1.TLSPrivateKeyKey)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to get client certificate: %w", err)
+		}
+		cfg.TLS.ClientCert, cfg.TLS.ClientKey = cert, key
+	}
+	return cfg, nil
+}
+
+func secretKey(ctx context.Context, c client.Client, namespace, name, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in secret %q", key, name)
+	}
+	return value, nil
+}
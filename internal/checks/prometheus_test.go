@@ -0,0 +1,78 @@
+package checks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/prometheus"
+)
+
+// TestCheckPrometheusMetric tests that query results go through reduce and the
+// threshold comparison
+func TestCheckPrometheusMetric(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "absent_thing" {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[` +
+			`{"metric":{"pod":"app-1"},"value":[1700000000,"0.01"]},{"metric":{"pod":"app-2"},"value":[1700000000,"0.07"]}]}}`))
+	}))
+	defer srv.Close()
+
+	check := func(query string, reduce dbupgradev1alpha1.ReduceFunction) dbupgradev1alpha1.MetricCheck {
+		return dbupgradev1alpha1.MetricCheck{
+			Name:       "error-rate",
+			Source:     dbupgradev1alpha1.MetricSourcePrometheus,
+			Prometheus: &dbupgradev1alpha1.PrometheusQuery{URL: srv.URL, Query: query},
+			Threshold: dbupgradev1alpha1.ThresholdSpec{
+				Operator: dbupgradev1alpha1.ThresholdOperatorLT,
+				Value:    resource.MustParse("0.05"),
+			},
+			Reduce: reduce,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		check   dbupgradev1alpha1.MetricCheck
+		passed  bool
+		reduced float64
+	}{
+		{name: "max over series fails", check: check("rate(errors[5m])", dbupgradev1alpha1.ReduceFunctionMax), passed: false, reduced: 0.07},
+		{name: "avg over series passes", check: check("rate(errors[5m])", dbupgradev1alpha1.ReduceFunctionAvg), passed: true, reduced: 0.04},
+		{name: "no series fails", check: check("absent_thing", ""), passed: false},
+	}
+
+	m := &MetricsChecker{prometheusClient: prometheus.NewClient(), prometheusHosts: []string{"127.0.0.1"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.checkSingleMetric(context.Background(), "default", tt.check)
+			if err != nil {
+				t.Fatalf("checkSingleMetric() error = %v", err)
+			}
+			if result.Passed != tt.passed || result.ReducedValue != tt.reduced {
+				t.Errorf("checkSingleMetric() = %+v, expected passed=%v reduced=%v", result, tt.passed, tt.reduced)
+			}
+			if !strings.Contains(result.Message, "error-rate") {
+				t.Errorf("message %q does not name the check", result.Message)
+			}
+		})
+	}
+
+	if _, err := (&MetricsChecker{}).checkSingleMetric(context.Background(), "default", tests[0].check); err == nil {
+		t.Error("checkSingleMetric() expected error without a prometheus client")
+	}
+
+	metadata := check("up", "")
+	metadata.Prometheus.URL = "http://169.254.169.254"
+	if _, err := m.checkSingleMetric(context.Background(), "default", metadata); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("checkSingleMetric() error = %v, expected host not allowed", err)
+	}
+}
@@ -0,0 +1,267 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// deploymentRevisionAnnotation is set by the Deployment controller on a
+// Deployment and its ReplicaSets
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// RolloutCheckResult contains the result of workload rollout checks
+type RolloutCheckResult struct {
+	Passed  bool
+	Message string
+	// Pending contains the workloads that are not fully rolled out
+	Pending []WorkloadRolloutStatus
+}
+
+// WorkloadRolloutStatus explains why a single workload is not rolled out
+type WorkloadRolloutStatus struct {
+	Kind   dbupgradev1alpha1.WorkloadKind
+	Name   string
+	Reason string
+}
+
+func (s WorkloadRolloutStatus) String() string {
+	return fmt.Sprintf("%s/%s: %s", s.Kind, s.Name, s.Reason)
+}
+
+// CheckWorkloadRollouts validates that every referenced or selected workload
+// is fully rolled out. All workloads are checked so the message lists each one
+// that is still rolling out.
+func CheckWorkloadRollouts(ctx context.Context, c client.Client, namespace string, checks []dbupgradev1alpha1.WorkloadRolloutCheck) (*RolloutCheckResult, error) {
+	var pending []WorkloadRolloutStatus
+	checked := 0
+
+	for _, check := range checks {
+		workloads, missing, err := listWorkloads(ctx, c, namespace, check)
+		if err != nil {
+			return nil, err
+		}
+		checked += len(missing)
+		pending = append(pending, missing...)
+		if len(workloads) == 0 && check.Selector != nil {
+			return &RolloutCheckResult{
+				Passed:  false,
+				Message: fmt.Sprintf("No Deployments, StatefulSets or DaemonSets found matching selector %s", metav1.FormatLabelSelector(check.Selector)),
+			}, nil
+		}
+
+		for _, w := range workloads {
+			reason, err := rolloutPendingReason(ctx, c, w)
+			if err != nil {
+				return nil, err
+			}
+			checked++
+			if reason != "" {
+				pending = append(pending, WorkloadRolloutStatus{Kind: workloadKind(w), Name: w.GetName(), Reason: reason})
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		reasons := make([]string, len(pending))
+		for i, p := range pending {
+			reasons[i] = p.String()
+		}
+		return &RolloutCheckResult{
+			Passed:  false,
+			Message: fmt.Sprintf("%d workload(s) not rolled out: %s", len(pending), strings.Join(reasons, "; ")),
+			Pending: pending,
+		}, nil
+	}
+
+	return &RolloutCheckResult{
+		Passed:  true,
+		Message: fmt.Sprintf("All %d workload(s) rolled out", checked),
+	}, nil
+}
+
+// listWorkloads gets the referenced workloads, or lists those matching the
+// selector. Missing references are returned separately so they are reported.
+func listWorkloads(ctx context.Context, c client.Client, namespace string, check dbupgradev1alpha1.WorkloadRolloutCheck) ([]client.Object, []WorkloadRolloutStatus, error) {
+	var workloads []client.Object
+	var missing []WorkloadRolloutStatus
+
+	for _, ref := range check.Workloads {
+		var obj client.Object
+		switch ref.Kind {
+		case dbupgradev1alpha1.WorkloadKindDeployment:
+			obj = &appsv1.Deployment{}
+		case dbupgradev1alpha1.WorkloadKindStatefulSet:
+			obj = &appsv1.StatefulSet{}
+		case dbupgradev1alpha1.WorkloadKindDaemonSet:
+			obj = &appsv1.DaemonSet{}
+		default:
+			return nil, nil, fmt.Errorf("unsupported workload kind %q", ref.Kind)
+		}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, obj); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, nil, fmt.Errorf("failed to get %s %s: %w", ref.Kind, ref.Name, err)
+			}
+			missing = append(missing, WorkloadRolloutStatus{Kind: ref.Kind, Name: ref.Name, Reason: "not found"})
+			continue
+		}
+		workloads = append(workloads, obj)
+	}
+
+	if check.Selector == nil {
+		return workloads, missing, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(check.Selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(ctx, deployments, opts...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, &deployments.Items[i])
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, opts...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		workloads = append(workloads, &statefulSets.Items[i])
+	}
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := c.List(ctx, daemonSets, opts...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		workloads = append(workloads, &daemonSets.Items[i])
+	}
+	return workloads, missing, nil
+}
+
+func workloadKind(obj client.Object) dbupgradev1alpha1.WorkloadKind {
+	switch obj.(type) {
+	case *appsv1.StatefulSet:
+		return dbupgradev1alpha1.WorkloadKindStatefulSet
+	case *appsv1.DaemonSet:
+		return dbupgradev1alpha1.WorkloadKindDaemonSet
+	}
+	return dbupgradev1alpha1.WorkloadKindDeployment
+}
+
+// rolloutPendingReason returns why the workload is not fully rolled out, or
+// "" if it is
+func rolloutPendingReason(ctx context.Context, c client.Client, obj client.Object) (string, error) {
+	if obj.GetDeletionTimestamp() != nil {
+		return "being deleted", nil
+	}
+
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		if reason := deploymentPendingReason(w); reason != "" {
+			return reason, nil
+		}
+		return oldReplicaSetsReason(ctx, c, w)
+	case *appsv1.StatefulSet:
+		return statefulSetPendingReason(w), nil
+	case *appsv1.DaemonSet:
+		return daemonSetPendingReason(w), nil
+	}
+	return "", fmt.Errorf("unsupported workload type %T", obj)
+}
+
+func deploymentPendingReason(d *appsv1.Deployment) string {
+	if d.Status.ObservedGeneration < d.Generation {
+		return fmt.Sprintf("generation %d not yet observed (observed %d)", d.Generation, d.Status.ObservedGeneration)
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return "progress deadline exceeded"
+		}
+	}
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas < desired {
+		return fmt.Sprintf("%d of %d replicas updated", d.Status.UpdatedReplicas, desired)
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		return fmt.Sprintf("%d old replica(s) pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	}
+	if d.Status.AvailableReplicas < desired {
+		return fmt.Sprintf("%d of %d updated replicas available", d.Status.AvailableReplicas, desired)
+	}
+	return ""
+}
+
+// oldReplicaSetsReason reports ReplicaSets from previous revisions that still
+// have pods, which the Deployment's replica counts can miss while they scale down
+func oldReplicaSetsReason(ctx context.Context, c client.Client, d *appsv1.Deployment) (string, error) {
+	replicaSets := &appsv1.ReplicaSetList{}
+	if err := c.List(ctx, replicaSets, client.InNamespace(d.Namespace)); err != nil {
+		return "", fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	revision := d.Annotations[deploymentRevisionAnnotation]
+	var old []string
+	for _, rs := range replicaSets.Items {
+		if !metav1.IsControlledBy(&rs, d) || rs.Annotations[deploymentRevisionAnnotation] == revision {
+			continue
+		}
+		if rs.Status.Replicas > 0 {
+			old = append(old, fmt.Sprintf("%s (%d pods)", rs.Name, rs.Status.Replicas))
+		}
+	}
+	if len(old) > 0 {
+		return fmt.Sprintf("old ReplicaSet(s) still have pods: %s", strings.Join(old, ", ")), nil
+	}
+	return "", nil
+}
+
+func statefulSetPendingReason(s *appsv1.StatefulSet) string {
+	if s.Status.ObservedGeneration < s.Generation {
+		return fmt.Sprintf("generation %d not yet observed (observed %d)", s.Generation, s.Status.ObservedGeneration)
+	}
+	desired := int32(1)
+	if s.Spec.Replicas != nil {
+		desired = *s.Spec.Replicas
+	}
+	if s.Status.UpdatedReplicas < desired {
+		return fmt.Sprintf("%d of %d replicas updated", s.Status.UpdatedReplicas, desired)
+	}
+	if s.Status.UpdateRevision != "" && s.Status.CurrentRevision != s.Status.UpdateRevision {
+		return fmt.Sprintf("pods of revision %s not yet replaced by %s", s.Status.CurrentRevision, s.Status.UpdateRevision)
+	}
+	if s.Status.Replicas > desired {
+		return fmt.Sprintf("%d extra replica(s) pending termination", s.Status.Replicas-desired)
+	}
+	if s.Status.AvailableReplicas < desired {
+		return fmt.Sprintf("%d of %d replicas available", s.Status.AvailableReplicas, desired)
+	}
+	return ""
+}
+
+func daemonSetPendingReason(ds *appsv1.DaemonSet) string {
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return fmt.Sprintf("generation %d not yet observed (observed %d)", ds.Generation, ds.Status.ObservedGeneration)
+	}
+	desired := ds.Status.DesiredNumberScheduled
+	if ds.Status.UpdatedNumberScheduled < desired {
+		return fmt.Sprintf("%d of %d pods updated", ds.Status.UpdatedNumberScheduled, desired)
+	}
+	if ds.Status.NumberAvailable < desired {
+		return fmt.Sprintf("%d of %d pods available", ds.Status.NumberAvailable, desired)
+	}
+	return ""
+}
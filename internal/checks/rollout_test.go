@@ -0,0 +1,115 @@
+package checks
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// TestDeploymentPendingReason tests the Deployment rollout states
+func TestDeploymentPendingReason(t *testing.T) {
+	deployment := func(generation, observed int64, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		status.ObservedGeneration = observed
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+			Status:     status,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		d        *appsv1.Deployment
+		expected string
+	}{
+		{
+			name:     "rolled out",
+			d:        deployment(2, 2, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			expected: "",
+		},
+		{
+			name:     "new spec not observed",
+			d:        deployment(3, 2, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			expected: "generation 3 not yet observed (observed 2)",
+		},
+		{
+			name:     "updating",
+			d:        deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 2, AvailableReplicas: 3}),
+			expected: "2 of 3 replicas updated",
+		},
+		{
+			name:     "old replicas scaling down",
+			d:        deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 4}),
+			expected: "1 old replica(s) pending termination",
+		},
+		{
+			name:     "updated but not available",
+			d:        deployment(2, 2, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 1}),
+			expected: "1 of 3 updated replicas available",
+		},
+		{
+			name: "stuck",
+			d: deployment(2, 2, appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"},
+			}}),
+			expected: "progress deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deploymentPendingReason(tt.d); got != tt.expected {
+				t.Errorf("deploymentPendingReason() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestStatefulSetAndDaemonSetPendingReason tests revisions and scheduled pods
+func TestStatefulSetAndDaemonSetPendingReason(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 4},
+		Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 4,
+			Replicas:           3,
+			UpdatedReplicas:    3,
+			AvailableReplicas:  3,
+			CurrentRevision:    "db-7c9f",
+			UpdateRevision:     "db-7c9f",
+		},
+	}
+	if got := statefulSetPendingReason(sts); got != "" {
+		t.Errorf("statefulSetPendingReason() = %q, expected rolled out", got)
+	}
+	sts.Status.CurrentRevision = "db-5b8d"
+	if got := statefulSetPendingReason(sts); got != "pods of revision db-5b8d not yet replaced by db-7c9f" {
+		t.Errorf("statefulSetPendingReason() = %q", got)
+	}
+	sts.Status.UpdatedReplicas = 1
+	if got := statefulSetPendingReason(sts); got != "1 of 3 replicas updated" {
+		t.Errorf("statefulSetPendingReason() = %q", got)
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     1,
+			DesiredNumberScheduled: 5,
+			UpdatedNumberScheduled: 5,
+			NumberAvailable:        4,
+		},
+	}
+	if got := daemonSetPendingReason(ds); got != "4 of 5 pods available" {
+		t.Errorf("daemonSetPendingReason() = %q", got)
+	}
+	ds.Status.NumberAvailable = 5
+	if got := daemonSetPendingReason(ds); got != "" {
+		t.Errorf("daemonSetPendingReason() = %q, expected rolled out", got)
+	}
+}
@@ -0,0 +1,68 @@
+package checks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// EvaluateSQLCheck compares the output of a SQL check container, which must be
+// a single numeric or boolean value, against the check's threshold. Booleans
+// count as 1 and 0. The result has the same shape as a metric check's, with
+// the query result as the only value.
+func EvaluateSQLCheck(check dbupgradev1alpha1.SQLCheck, output string) (*MetricCheckResult, error) {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		// psql prints NULL as an empty line
+		return nil, fmt.Errorf("query returned no rows or NULL, expected exactly one value")
+	}
+	if len(lines) > 1 {
+		return nil, fmt.Errorf("query returned %d rows, expected exactly one value", len(lines))
+	}
+
+	value, err := parseSQLValue(lines[0])
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdValue := check.Threshold.Value.AsApproximateFloat64()
+	passed := compareThreshold(value, thresholdValue, check.Threshold.Operator)
+	verb := "satisfies"
+	if !passed {
+		verb = "does not satisfy"
+	}
+	return &MetricCheckResult{
+		Passed:         passed,
+		Message:        fmt.Sprintf("SQL check %s value %.4f %s %s %.4f", check.Name, value, verb, check.Threshold.Operator, thresholdValue),
+		Values:         []float64{value},
+		ReducedValue:   value,
+		ThresholdValue: thresholdValue,
+	}, nil
+}
+
+// parseSQLValue parses a value as printed by psql (t/f) or mysql (NULL, 0/1)
+func parseSQLValue(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case "t", "true":
+		return 1, nil
+	case "f", "false":
+		return 0, nil
+	case "null":
+		return 0, fmt.Errorf("query returned NULL")
+	}
+	if strings.Contains(s, "\t") || strings.Contains(s, "|") {
+		return 0, fmt.Errorf("query returned more than one column: %q", s)
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("query returned %q, expected a number or boolean", s)
+	}
+	return value, nil
+}
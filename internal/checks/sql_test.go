@@ -0,0 +1,57 @@
+package checks
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// TestEvaluateSQLCheck tests parsing psql and mysql output and the threshold comparison
+func TestEvaluateSQLCheck(t *testing.T) {
+	check := dbupgradev1alpha1.SQLCheck{
+		Name: "legacy-orders",
+		Threshold: dbupgradev1alpha1.ThresholdSpec{
+			Operator: dbupgradev1alpha1.ThresholdOperatorLTE,
+			Value:    resource.MustParse("0"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		output   string
+		passed   bool
+		expected float64
+		wantErr  bool
+	}{
+		{name: "zero rows", output: "0\n", passed: true, expected: 0},
+		{name: "rows left", output: "  42\n", passed: false, expected: 42},
+		{name: "psql boolean", output: "f\n", passed: true, expected: 0},
+		{name: "psql true", output: "t\n", passed: false, expected: 1},
+		{name: "decimal", output: "0.25\n", passed: false, expected: 0.25},
+		{name: "psql NULL or no rows", output: "\n", wantErr: true},
+		{name: "mysql NULL", output: "NULL\n", wantErr: true},
+		{name: "two rows", output: "1\n2\n", wantErr: true},
+		{name: "two columns", output: "1\t2\n", wantErr: true},
+		{name: "text", output: "orders\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvaluateSQLCheck(check, tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EvaluateSQLCheck() expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvaluateSQLCheck() error = %v", err)
+			}
+			if result.Passed != tt.passed || result.ReducedValue != tt.expected {
+				t.Errorf("EvaluateSQLCheck() = %+v, expected passed=%v value=%v", result, tt.passed, tt.expected)
+			}
+		})
+	}
+}
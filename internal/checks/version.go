@@ -2,9 +2,13 @@ package checks
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
 	corev1 "k8s.io/api/core/v1"
@@ -12,15 +16,22 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
 )
 
+// imageVersionLabel is the image config label read by versionSource=imageLabel
+const imageVersionLabel = "org.opencontainers.image.version"
+
+// maxCachedImageVersions bounds the image version cache; it is cleared when full
+const maxCachedImageVersions = 1024
+
 // VersionCheckResult contains the result of a version check
 type VersionCheckResult struct {
 	Passed  bool
 	Message string
 	// FailedPods contains pods that failed the check (if any)
 	FailedPods []PodVersionInfo
-	// SkippedPods contains pods that were skipped due to non-semver tags (when strictMode=false)
+	// SkippedPods contains pods that were skipped due to non-semver versions (when strictMode=false)
 	SkippedPods []PodVersionInfo
 }
 
@@ -33,10 +44,35 @@ type PodVersionInfo struct {
 	Version       string
 }
 
-// CheckMinPodVersions validates that all pods matching the selector have at least the minimum version
-func CheckMinPodVersions(ctx context.Context, c client.Client, namespace string, checks []dbupgradev1alpha1.MinPodVersionCheck) (*VersionCheckResult, error) {
+// ImageVersionResolver reads the version label of container images from their
+// registry. Labels are cached by image digest, which makes them immutable, and
+// pull credentials, so it should be created once at startup and reused across
+// reconciles.
+type ImageVersionResolver struct {
+	registryClient *registry.Client
+	// platform selects the image of a multi-arch index whose labels are read
+	platform registry.Platform
+
+	mu sync.Mutex
+	// versions are keyed by versionCacheKey
+	versions map[string]string
+}
+
+// NewImageVersionResolver creates a resolver that reads labels of the
+// linux image for the operator's own architecture
+func NewImageVersionResolver(registryClient *registry.Client) *ImageVersionResolver {
+	return &ImageVersionResolver{
+		registryClient: registryClient,
+		platform:       registry.Platform{OS: "linux", Architecture: runtime.GOARCH},
+		versions:       map[string]string{},
+	}
+}
+
+// CheckMinPodVersions validates that all pods matching the selector have at least the minimum version.
+// images is only used by checks with versionSource=imageLabel.
+func CheckMinPodVersions(ctx context.Context, c client.Client, images *ImageVersionResolver, namespace string, checks []dbupgradev1alpha1.MinPodVersionCheck) (*VersionCheckResult, error) {
 	for _, check := range checks {
-		result, err := checkSinglePodVersion(ctx, c, namespace, check)
+		result, err := checkSinglePodVersion(ctx, c, images, namespace, check)
 		if err != nil {
 			return nil, err
 		}
@@ -51,7 +87,7 @@ func CheckMinPodVersions(ctx context.Context, c client.Client, namespace string,
 	}, nil
 }
 
-func checkSinglePodVersion(ctx context.Context, c client.Client, namespace string, check dbupgradev1alpha1.MinPodVersionCheck) (*VersionCheckResult, error) {
+func checkSinglePodVersion(ctx context.Context, c client.Client, images *ImageVersionResolver, namespace string, check dbupgradev1alpha1.MinPodVersionCheck) (*VersionCheckResult, error) {
 	// Convert LabelSelector to labels.Selector
 	selector, err := metav1.LabelSelectorAsSelector(&check.Selector)
 	if err != nil {
@@ -103,8 +139,10 @@ func checkSinglePodVersion(ctx context.Context, c client.Client, namespace strin
 				ImageTag:      container.Image,
 			}
 
-			// Extract version from image tag
-			imageVersion := extractVersionFromImage(container.Image)
+			imageVersion, err := podVersion(ctx, c, images, &pod, container, check.VersionSource)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get version of pod %s container %s: %w", pod.Name, container.Name, err)
+			}
 			if imageVersion == "" {
 				podInfo.Version = "unknown"
 				if strictMode {
@@ -154,7 +192,7 @@ func checkSinglePodVersion(ctx context.Context, c client.Client, namespace strin
 	if len(failedPods) > 0 {
 		msg := fmt.Sprintf("%d pod(s) have version below minimum %s", len(failedPods), check.MinVersion)
 		if len(skippedPods) > 0 {
-			msg += fmt.Sprintf(" (%d skipped due to non-semver tags)", len(skippedPods))
+			msg += fmt.Sprintf(" (%d skipped due to non-semver versions)", len(skippedPods))
 		}
 		return &VersionCheckResult{
 			Passed:      false,
@@ -168,14 +206,14 @@ func checkSinglePodVersion(ctx context.Context, c client.Client, namespace strin
 	if checkedCount == 0 && len(skippedPods) > 0 {
 		return &VersionCheckResult{
 			Passed:      false,
-			Message:     fmt.Sprintf("No pods with semver tags found (%d skipped); cannot validate versions", len(skippedPods)),
+			Message:     fmt.Sprintf("No pods with semver versions found (%d skipped); cannot validate versions", len(skippedPods)),
 			SkippedPods: skippedPods,
 		}, nil
 	}
 
 	msg := fmt.Sprintf("All %d pod(s) meet minimum version %s", checkedCount, check.MinVersion)
 	if len(skippedPods) > 0 {
-		msg += fmt.Sprintf(" (%d skipped due to non-semver tags)", len(skippedPods))
+		msg += fmt.Sprintf(" (%d skipped due to non-semver versions)", len(skippedPods))
 	}
 	return &VersionCheckResult{
 		Passed:      true,
@@ -184,6 +222,128 @@ func checkSinglePodVersion(ctx context.Context, c client.Client, namespace strin
 	}, nil
 }
 
+// podVersion reads the version of a pod's container from source. It returns ""
+// if the label, annotation, variable or image label is not set.
+func podVersion(ctx context.Context, c client.Client, images *ImageVersionResolver, pod *corev1.Pod, container corev1.Container, source string) (string, error) {
+	switch {
+	case source == "" || source == dbupgradev1alpha1.VersionSourceImageTag:
+		return extractVersionFromImage(container.Image), nil
+	case source == dbupgradev1alpha1.VersionSourceImageLabel:
+		if images == nil {
+			return "", fmt.Errorf("versionSource=imageLabel requires a registry client")
+		}
+		return images.version(ctx, c, pod, container)
+	case strings.HasPrefix(source, dbupgradev1alpha1.VersionSourceLabelPrefix):
+		return strings.TrimSpace(pod.Labels[strings.TrimPrefix(source, dbupgradev1alpha1.VersionSourceLabelPrefix)]), nil
+	case strings.HasPrefix(source, dbupgradev1alpha1.VersionSourceAnnotationPrefix):
+		return strings.TrimSpace(pod.Annotations[strings.TrimPrefix(source, dbupgradev1alpha1.VersionSourceAnnotationPrefix)]), nil
+	case strings.HasPrefix(source, dbupgradev1alpha1.VersionSourceEnvPrefix):
+		// Only literal values: valueFrom would need the kubelet's resolution
+		name := strings.TrimPrefix(source, dbupgradev1alpha1.VersionSourceEnvPrefix)
+		for _, env := range container.Env {
+			if env.Name == name {
+				return strings.TrimSpace(env.Value), nil
+			}
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("unsupported versionSource %q", source)
+}
+
+// version returns the version label of the image the container runs. The
+// digest the kubelet pulled is preferred over the tag, which may have moved.
+func (r *ImageVersionResolver) version(ctx context.Context, c client.Client, pod *corev1.Pod, container corev1.Container) (string, error) {
+	return r.imageVersion(ctx, runningImage(pod, container), pullSecretsKeychain(ctx, c, pod))
+}
+
+// imageVersion returns the version label of image, pulled with keychain
+func (r *ImageVersionResolver) imageVersion(ctx context.Context, image string, keychain registry.Keychain) (string, error) {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	// Only digests are cached: a tag can be pushed again with another version.
+	// The credentials are part of the key, so a pod whose pull secrets cannot
+	// read the image is not answered from another pod's pull.
+	key := versionCacheKey(image, keychain[ref.Registry])
+	if ref.Digest != "" {
+		r.mu.Lock()
+		version, ok := r.versions[key]
+		r.mu.Unlock()
+		if ok {
+			return version, nil
+		}
+	}
+
+	img, err := r.registryClient.Pull(ctx, image, r.platform, keychain)
+	if err != nil {
+		return "", err
+	}
+	labels, err := img.Labels(ctx)
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimSpace(labels[imageVersionLabel])
+
+	if ref.Digest != "" {
+		r.mu.Lock()
+		if len(r.versions) >= maxCachedImageVersions {
+			r.versions = map[string]string{}
+		}
+		r.versions[key] = version
+		r.mu.Unlock()
+	}
+	return version, nil
+}
+
+// versionCacheKey identifies image pulled with creds, hashing the password
+// so it is not kept in memory
+func versionCacheKey(image string, creds registry.Credentials) string {
+	if creds == (registry.Credentials{}) {
+		return image
+	}
+	sum := sha256.Sum256([]byte(creds.Username + "\x00" + creds.Password))
+	return image + " " + hex.EncodeToString(sum[:])
+}
+
+// runningImage returns the container image pinned to the digest reported in
+// the pod status, or the image of the spec if the status has no digest yet
+func runningImage(pod *corev1.Pod, container corev1.Container) string {
+	statuses := append(pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses...)
+	for _, status := range statuses {
+		if status.Name != container.Name {
+			continue
+		}
+		// docker reports docker-pullable://repo@sha256:..., containerd repo@sha256:...
+		imageID := strings.TrimPrefix(status.ImageID, "docker-pullable://")
+		if idx := strings.LastIndex(imageID, "@"); idx != -1 {
+			return registry.PinDigest(container.Image, imageID[idx+1:])
+		}
+	}
+	return container.Image
+}
+
+// pullSecretsKeychain merges the docker configs of the pod's image pull
+// secrets. Like the kubelet, it ignores secrets that are missing or invalid.
+func pullSecretsKeychain(ctx context.Context, c client.Client, pod *corev1.Pod) registry.Keychain {
+	keychain := registry.Keychain{}
+	for _, ref := range pod.Spec.ImagePullSecrets {
+		data, err := secretKey(ctx, c, pod.Namespace, ref.Name, corev1.DockerConfigJsonKey)
+		if err != nil {
+			continue
+		}
+		secretKeychain, err := registry.ParseDockerConfig(data)
+		if err != nil {
+			continue
+		}
+		for host, creds := range secretKeychain {
+			keychain[host] = creds
+		}
+	}
+	return keychain
+}
+
 // extractVersionFromImage extracts the version tag from an image reference
 // Handles formats like:
 // - nginx:1.21.0
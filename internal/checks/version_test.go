@@ -0,0 +1,192 @@
+package checks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+)
+
+// TestPodVersion tests reading versions from tags, labels, annotations and env
+func TestPodVersion(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "orders-7c9f",
+			Labels:      map[string]string{"app.kubernetes.io/version": "2.4.1"},
+			Annotations: map[string]string{"example.com/release": "v2.5.0"},
+		},
+	}
+	container := corev1.Container{
+		Name:  "app",
+		Image: "registry.example.com/orders@sha256:" + strings.Repeat("a", 64),
+		Env:   []corev1.EnvVar{{Name: "APP_VERSION", Value: " 2.3.0 "}},
+	}
+
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{source: "", expected: ""},
+		{source: "imageTag", expected: ""},
+		{source: "label:app.kubernetes.io/version", expected: "2.4.1"},
+		{source: "label:missing", expected: ""},
+		{source: "annotation:example.com/release", expected: "v2.5.0"},
+		{source: "env:APP_VERSION", expected: "2.3.0"},
+		{source: "env:MISSING", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, err := podVersion(context.Background(), nil, nil, pod, container, tt.source)
+			if err != nil {
+				t.Fatalf("podVersion() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("podVersion() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := podVersion(context.Background(), nil, nil, pod, container, "imageLabel"); err == nil {
+		t.Error("podVersion() with imageLabel and no resolver should fail")
+	}
+}
+
+// TestImageVersionResolver tests reading and caching the OCI version label
+func TestImageVersionResolver(t *testing.T) {
+	config := []byte(`{"config":{"Labels":{"org.opencontainers.image.version":"3.1.0"}}}`)
+	configDigest := sha256Digest(config)
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"config":{"digest":%q,"size":%d},"layers":[]}`, configDigest, len(config)))
+	manifestDigest := sha256Digest(manifest)
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The client pings /v2/ before every lookup; count the lookups only
+		if r.URL.Path == "/v2/" {
+			return
+		}
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/v2/orders/manifests/" + manifestDigest:
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(manifest)
+		case "/v2/orders/blobs/" + configDigest:
+			_, _ = w.Write(config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ImageID: host + "/orders@" + manifestDigest},
+		}},
+	}
+	container := corev1.Container{Name: "app", Image: host + "/orders:main"}
+	resolver := NewImageVersionResolver(registry.NewClient(true))
+
+	for i := 0; i < 2; i++ {
+		got, err := podVersion(context.Background(), nil, resolver, pod, container, "imageLabel")
+		if err != nil {
+			t.Fatalf("podVersion() error = %v", err)
+		}
+		if got != "3.1.0" {
+			t.Errorf("podVersion() = %q, expected 3.1.0", got)
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("registry got %d requests, expected 2 (second lookup cached)", n)
+	}
+}
+
+// TestImageVersionResolverCredentials tests that a cached version is not
+// returned for credentials that cannot read the image
+func TestImageVersionResolverCredentials(t *testing.T) {
+	config := []byte(`{"config":{"Labels":{"org.opencontainers.image.version":"3.1.0"}}}`)
+	configDigest := sha256Digest(config)
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"config":{"digest":%q,"size":%d},"layers":[]}`, configDigest, len(config)))
+	manifestDigest := sha256Digest(manifest)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			return
+		}
+		if username, password, _ := r.BasicAuth(); username != "ci" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/private/manifests/" + manifestDigest:
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(manifest)
+		case "/v2/private/blobs/" + configDigest:
+			_, _ = w.Write(config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	image := host + "/private@" + manifestDigest
+	resolver := NewImageVersionResolver(registry.NewClient(true))
+
+	got, err := resolver.imageVersion(context.Background(), image, registry.Keychain{host: {Username: "ci", Password: "secret"}})
+	if err != nil || got != "3.1.0" {
+		t.Fatalf("imageVersion() with credentials = %q, %v, expected 3.1.0", got, err)
+	}
+	for name, keychain := range map[string]registry.Keychain{
+		"anonymous":      {},
+		"wrong password": {host: {Username: "ci", Password: "guess"}},
+	} {
+		if _, err := resolver.imageVersion(context.Background(), image, keychain); !errors.Is(err, registry.ErrUnauthorized) {
+			t.Errorf("imageVersion() %s error = %v, expected ErrUnauthorized", name, err)
+		}
+	}
+}
+
+// TestRunningImage tests pinning the spec image to the digest in the pod status
+func TestRunningImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("b", 64)
+	container := corev1.Container{Name: "app", Image: "ghcr.io/acme/orders:2.4.1"}
+
+	tests := []struct {
+		name     string
+		imageID  string
+		expected string
+	}{
+		{name: "containerd", imageID: "ghcr.io/acme/orders@" + digest, expected: "ghcr.io/acme/orders@" + digest},
+		{name: "docker", imageID: "docker-pullable://ghcr.io/acme/orders@" + digest, expected: "ghcr.io/acme/orders@" + digest},
+		{name: "image id only", imageID: digest, expected: "ghcr.io/acme/orders:2.4.1"},
+		{name: "not started", imageID: "", expected: "ghcr.io/acme/orders:2.4.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", ImageID: tt.imageID},
+			}}}
+			if got := runningImage(pod, container); got != tt.expected {
+				t.Errorf("runningImage() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,50 @@
+// Package dburl builds the canonical connection URLs the operator writes into
+// its Secret. Engines parse them back into their own formats.
+package dburl
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// URL describes a canonical connection URL built by the operator
+// (IAM tokens, Secrets Manager, Vault or a structured connection spec)
+type URL struct {
+	// Scheme is postgres, mysql or maria
+	Scheme   string
+	Username string
+	Password string
+	Host     string
+	Port     int32
+	Database string
+	// RawQuery is the encoded query string, without the leading '?'
+	RawQuery string
+}
+
+// String renders the URL with the credentials escaped, so any character is
+// safe in a username, password or IAM token
+func (u URL) String() string {
+	var creds string
+	if u.Username != "" {
+		creds = escapeUserinfo(u.Username)
+		if u.Password != "" {
+			creds += ":" + escapeUserinfo(u.Password)
+		}
+		creds += "@"
+	}
+
+	out := fmt.Sprintf("%s://%s%s/%s", u.Scheme, creds, net.JoinHostPort(u.Host, strconv.Itoa(int(u.Port))), u.Database)
+	if u.RawQuery != "" {
+		out += "?" + u.RawQuery
+	}
+	return out
+}
+
+// escapeUserinfo query-escapes s, except that spaces become %20: parsers keep
+// '+' in userinfo as a literal plus sign
+func escapeUserinfo(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
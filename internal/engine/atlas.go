@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// atlasEngine runs `atlas migrate apply` against the canonical URL
+type atlasEngine struct {
+	spec *dbupgradev1alpha1.AtlasSpec
+}
+
+func (e *atlasEngine) Name() dbupgradev1alpha1.MigrationEngineType {
+	return dbupgradev1alpha1.MigrationEngineAtlas
+}
+
+// SecretData returns nothing extra - Atlas understands the canonical URL
+func (e *atlasEngine) SecretData(databaseURL string) (map[string][]byte, error) {
+	return nil, nil
+}
+
+// atlasConfigFile is the project file holding the connection in File mode
+const atlasConfigFile = "atlas.hcl"
+
+// atlasEnv is the env block of atlasConfigFile
+const atlasEnv = "dbupgrade"
+
+// ConnectionFiles renders an Atlas project file whose env block holds the URL
+func (e *atlasEngine) ConnectionFiles(databaseURL string) (map[string][]byte, error) {
+	// HCL strings interpolate ${...} and %{...}; escape them along with quotes
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", "$${", "%{", "%%{")
+	config := fmt.Sprintf("env %q {\n  url = \"%s\"\n}\n", atlasEnv, escaper.Replace(databaseURL))
+	return map[string][]byte{atlasConfigFile: []byte(config)}, nil
+}
+
+// Container runs `atlas migrate apply` with JSON output so the applied
+// revision can be read back by ParseRevision
+func (e *atlasEngine) Container(opts Options) corev1.Container {
+	container := e.container(opts, "apply")
+	container.Args = append(container.Args, "--format", "{{ json . }}")
+	return container
+}
+
+// atlasApplyOutput is the subset of `atlas migrate apply --format '{{ json . }}'`
+// the operator reads
+type atlasApplyOutput struct {
+	Current string
+	Target  string
+	Applied []struct {
+		Name    string
+		Version string
+	}
+}
+
+func (e *atlasEngine) ParseRevision(output string) Revision {
+	// The JSON document is the last line; anything before it is log noise
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var out atlasApplyOutput
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &out); err != nil {
+		return Revision{}
+	}
+
+	rev := Revision{Version: out.Target, Applied: len(out.Applied)}
+	if rev.Version == "" {
+		rev.Version = out.Current
+	}
+	if n := len(out.Applied); n > 0 {
+		rev.LastFile = out.Applied[n-1].Name
+		rev.Version = out.Applied[n-1].Version
+	}
+	return rev
+}
+
+// DownContainer runs `atlas migrate down --to-version`. Atlas computes the
+// revert plan against the dev database from atlas.devURLSecretRef.
+func (e *atlasEngine) DownContainer(opts Options) corev1.Container {
+	container := e.container(opts, "down")
+	if e.spec != nil && e.spec.DevURLSecretRef != nil {
+		container.Args = append(container.Args, "--dev-url", "$(DEV_URL)")
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:      "DEV_URL",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: e.spec.DevURLSecretRef},
+		})
+	}
+	return container
+}
+
+func (e *atlasEngine) container(opts Options, command string) corev1.Container {
+	args := []string{"--dir", fmt.Sprintf("file://%s", migrationsPath(opts.Dir))}
+	env := []corev1.EnvVar{secretEnv("DATABASE_URL", opts.SecretName, URLKey)}
+	mounts := migrationsMount()
+	if opts.ConnectionFile {
+		args = append(args, "--config", "file://"+connectionFilePath(atlasConfigFile), "--env", atlasEnv)
+		env = nil
+		mounts = append(mounts, connectionMount())
+	} else {
+		args = append(args, "--url", "$(DATABASE_URL)")
+	}
+	if e.spec != nil && e.spec.RevisionsSchema != "" {
+		args = append(args, "--revisions-schema", e.spec.RevisionsSchema)
+	}
+	if opts.TargetVersion != "" {
+		args = append(args, "--to-version", opts.TargetVersion)
+	}
+
+	return corev1.Container{
+		Name:         ContainerName,
+		Image:        AtlasImage,
+		Command:      []string{"/atlas", "migrate", command},
+		Args:         args,
+		Env:          env,
+		VolumeMounts: mounts,
+	}
+}
+
+// atlasPendingPattern matches "-- migrating version 20240101000001" in dry-run output
+var atlasPendingPattern = regexp.MustCompile(`(?m)^\s*-- migrating version (\S+)`)
+
+// PlanContainer runs `atlas migrate apply --dry-run`, which prints the
+// pending versions and the SQL statements they would execute
+func (e *atlasEngine) PlanContainer(opts Options) corev1.Container {
+	container := e.container(opts, "apply")
+	container.Args = append(container.Args, "--dry-run")
+	return container
+}
+
+func (e *atlasEngine) PendingMigrations(output string) []string {
+	return matchAll(atlasPendingPattern, output)
+}
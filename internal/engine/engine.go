@@ -0,0 +1,212 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/env"
+)
+
+// Runner images for each engine. Override via env vars (Helm/Kustomize).
+var (
+	// AtlasImage is the official Atlas CLI image
+	AtlasImage = env.GetOrDefault("ATLAS_IMAGE", "arigaio/atlas:latest")
+
+	// GolangMigrateImage is the golang-migrate CLI image
+	GolangMigrateImage = env.GetOrDefault("GOLANG_MIGRATE_IMAGE", "migrate/migrate:v4.17.0")
+
+	// FlywayImage is the Flyway community edition image
+	FlywayImage = env.GetOrDefault("FLYWAY_IMAGE", "flyway/flyway:10")
+
+	// GooseImage is a goose CLI image (goose has no official image)
+	GooseImage = env.GetOrDefault("GOOSE_IMAGE", "ghcr.io/kukymbr/goose-docker:3.19.2")
+
+	// LiquibaseImage is the official Liquibase image
+	LiquibaseImage = env.GetOrDefault("LIQUIBASE_IMAGE", "liquibase/liquibase:4.26")
+)
+
+const (
+	// MigrationsVolume is the shared volume the init container extracts into
+	MigrationsVolume = "migrations"
+
+	// MigrationsMountPath is where the runner sees the extracted files
+	MigrationsMountPath = "/migrations"
+
+	// ContainerName is the name of the runner container in the Job
+	ContainerName = "migrate"
+
+	// URLKey is the canonical connection URL key in the operator-managed Secret
+	URLKey = "url"
+
+	// ConnectionVolume projects the operator-managed Secret for runners that
+	// read the connection from a config file (Options.ConnectionFile)
+	ConnectionVolume = "connection"
+
+	// ConnectionMountPath is where those runners see the Secret's keys
+	ConnectionMountPath = "/etc/dbupgrade/connection"
+)
+
+// Options describes the Job-specific inputs an engine needs to render its container
+type Options struct {
+	// Dir is the migrations directory inside the image (e.g. /migrations)
+	Dir string
+	// SecretName is the operator-managed connection Secret
+	SecretName string
+	// TargetVersion stops the migration at this version (empty = latest)
+	TargetVersion string
+	// ConnectionFile makes the runner read the connection from the config
+	// file under ConnectionMountPath instead of env vars (see ConnectionFiler)
+	ConnectionFile bool
+}
+
+// MigrationEngine renders the runner container for a migration tool.
+// Implementations must be stateless; the controller creates one per reconcile.
+type MigrationEngine interface {
+	// Name returns the engine identifier used in spec.migrations.engine
+	Name() dbupgradev1alpha1.MigrationEngineType
+
+	// SecretData derives the extra keys this engine reads from the
+	// operator-managed Secret. databaseURL is the canonical URL stored
+	// under URLKey (postgres://... or mysql://...).
+	SecretData(databaseURL string) (map[string][]byte, error)
+
+	// Container renders the runner container: image, command, args, env
+	// and volume mounts.
+	Container(opts Options) corev1.Container
+
+	// ParseRevision extracts the applied revision from the output of a
+	// successful Container run
+	ParseRevision(output string) Revision
+}
+
+// Revision is what a successful apply reports about the database
+type Revision struct {
+	// Version the database is at after the run ("" if the engine didn't say)
+	Version string
+	// Applied is the number of migrations applied by the run
+	Applied int
+	// LastFile is the last migration applied by the run
+	LastFile string
+}
+
+// Planner is implemented by engines that can report pending migrations
+// without applying them (spec.mode=Plan).
+type Planner interface {
+	// PlanContainer renders the runner container for a dry-run/status command
+	PlanContainer(opts Options) corev1.Container
+
+	// PendingMigrations extracts the pending migrations from the plan output
+	PendingMigrations(output string) []string
+}
+
+// Downgrader is implemented by engines that can migrate down to
+// Options.TargetVersion (spec.migrations.allowDowngrade).
+type Downgrader interface {
+	// DownContainer renders the runner container that reverts applied
+	// migrations newer than the target version
+	DownContainer(opts Options) corev1.Container
+}
+
+// ConnectionFiler is implemented by engines whose runner can read the
+// connection from a config file (spec.runner.connectionMode=File), which keeps
+// credentials out of the process environment.
+type ConnectionFiler interface {
+	// ConnectionFiles renders the config files the runner reads, keyed by
+	// file name. They are stored in the operator-managed Secret.
+	ConnectionFiles(databaseURL string) (map[string][]byte, error)
+}
+
+// New returns the MigrationEngine configured by the migrations spec.
+// An empty engine defaults to Atlas.
+func New(spec dbupgradev1alpha1.MigrationsSpec) (MigrationEngine, error) {
+	switch spec.Engine {
+	case dbupgradev1alpha1.MigrationEngineAtlas, "":
+		return &atlasEngine{spec: spec.Atlas}, nil
+	case dbupgradev1alpha1.MigrationEngineGolangMigrate:
+		return &golangMigrateEngine{}, nil
+	case dbupgradev1alpha1.MigrationEngineFlyway:
+		return &flywayEngine{spec: spec.Flyway}, nil
+	case dbupgradev1alpha1.MigrationEngineGoose:
+		return &gooseEngine{}, nil
+	case dbupgradev1alpha1.MigrationEngineLiquibase:
+		if spec.Liquibase == nil {
+			return nil, fmt.Errorf("migrations.liquibase is required for engine liquibase")
+		}
+		return &liquibaseEngine{spec: spec.Liquibase}, nil
+	default:
+		return nil, fmt.Errorf("unsupported migration engine: %s", spec.Engine)
+	}
+}
+
+// migrationsPath returns the absolute path of the migration files in the runner
+func migrationsPath(dir string) string {
+	return MigrationsMountPath + dir
+}
+
+// migrationsMount mounts the shared migrations volume into the runner
+func migrationsMount() []corev1.VolumeMount {
+	return []corev1.VolumeMount{{
+		Name:      MigrationsVolume,
+		MountPath: MigrationsMountPath,
+	}}
+}
+
+// connectionMount mounts the projected operator-managed Secret into the runner
+func connectionMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      ConnectionVolume,
+		MountPath: ConnectionMountPath,
+		ReadOnly:  true,
+	}
+}
+
+// connectionFilePath returns the path of a connection config file in the runner
+func connectionFilePath(name string) string {
+	return ConnectionMountPath + "/" + name
+}
+
+// propertiesFile renders Java properties, escaping values so credentials
+// with backslashes or line breaks survive
+func propertiesFile(entries [][2]string) []byte {
+	escaper := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	var b strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s=%s\n", e[0], escaper.Replace(e[1]))
+	}
+	return []byte(b.String())
+}
+
+// secretEnv builds an env var sourced from a key of the operator-managed Secret
+func secretEnv(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// lastMatch returns the first capture group of the last match in output
+func lastMatch(pattern *regexp.Regexp, output string) string {
+	matches := pattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
+
+// matchAll returns the first capture group of every match in output
+func matchAll(pattern *regexp.Regexp, output string) []string {
+	var out []string
+	for _, m := range pattern.FindAllStringSubmatch(output, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// Secret keys shared by the JDBC-based engines (Flyway, Liquibase)
+const (
+	jdbcURLKey  = "jdbc-url"
+	usernameKey = "username"
+	passwordKey = "password"
+)
+
+// flywayEngine runs `flyway migrate`. Credentials are passed through
+// FLYWAY_* env vars, which Flyway reads natively.
+type flywayEngine struct {
+	spec *dbupgradev1alpha1.FlywaySpec
+}
+
+func (e *flywayEngine) Name() dbupgradev1alpha1.MigrationEngineType {
+	return dbupgradev1alpha1.MigrationEngineFlyway
+}
+
+func (e *flywayEngine) SecretData(databaseURL string) (map[string][]byte, error) {
+	return jdbcSecretData(databaseURL)
+}
+
+func (e *flywayEngine) Container(opts Options) corev1.Container {
+	args := []string{"-locations=filesystem:" + migrationsPath(opts.Dir)}
+	if e.spec != nil && e.spec.BaselineOnMigrate {
+		args = append(args, "-baselineOnMigrate=true")
+	}
+	if opts.TargetVersion != "" {
+		args = append(args, "-target="+opts.TargetVersion)
+	}
+	args = append(args, "migrate")
+
+	container := corev1.Container{
+		Name:    ContainerName,
+		Image:   FlywayImage,
+		Command: []string{"flyway"},
+		Args:    args,
+		Env: []corev1.EnvVar{
+			secretEnv("FLYWAY_URL", opts.SecretName, jdbcURLKey),
+			secretEnv("FLYWAY_USER", opts.SecretName, usernameKey),
+			secretEnv("FLYWAY_PASSWORD", opts.SecretName, passwordKey),
+		},
+		VolumeMounts: migrationsMount(),
+	}
+	if opts.ConnectionFile {
+		container.Args = append([]string{"-configFiles=" + connectionFilePath(flywayConfigFile)}, container.Args...)
+		container.Env = nil
+		container.VolumeMounts = append(container.VolumeMounts, connectionMount())
+	}
+	return container
+}
+
+// flywayConfigFile holds the connection in File mode
+const flywayConfigFile = "flyway.conf"
+
+// ConnectionFiles renders a Flyway config file with the JDBC URL and credentials
+func (e *flywayEngine) ConnectionFiles(databaseURL string) (map[string][]byte, error) {
+	data, err := jdbcSecretData(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{flywayConfigFile: propertiesFile([][2]string{
+		{"flyway.url", string(data[jdbcURLKey])},
+		{"flyway.user", string(data[usernameKey])},
+		{"flyway.password", string(data[passwordKey])},
+	})}, nil
+}
+
+// jdbcSecretData splits the canonical URL into a JDBC URL and separate credentials
+func jdbcSecretData(databaseURL string) (map[string][]byte, error) {
+	info, err := parseDatabaseURL(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		jdbcURLKey:  []byte(info.jdbcURL()),
+		usernameKey: []byte(info.username),
+		passwordKey: []byte(info.password),
+	}, nil
+}
+
+// flywayPendingPattern matches a Pending row of the `flyway info` table:
+// | Versioned | 2 | create posts | SQL | | Pending |
+var flywayPendingPattern = regexp.MustCompile(`(?m)^\|\s*\w+\s*\|\s*([^|]*?)\s*\|\s*([^|]*?)\s*\|.*\|\s*Pending\s*\|`)
+
+// PlanContainer runs `flyway info`, which lists applied and pending migrations
+func (e *flywayEngine) PlanContainer(opts Options) corev1.Container {
+	container := e.Container(opts)
+	container.Args = append(container.Args[:len(container.Args)-1], "info")
+	return container
+}
+
+func (e *flywayEngine) PendingMigrations(output string) []string {
+	var pending []string
+	for _, m := range flywayPendingPattern.FindAllStringSubmatch(output, -1) {
+		pending = append(pending, strings.TrimSpace(m[1]+" "+m[2]))
+	}
+	return pending
+}
+
+var (
+	// flywayAppliedPattern matches `Migrating schema "public" to version "2 - create posts"`
+	flywayAppliedPattern = regexp.MustCompile(`(?m)Migrating schema \S+ to version "([^"]+?)(?: - ([^"]*))?"`)
+
+	// flywayVersionPattern matches "now at version v2" after a migrate, or
+	// `Current version of schema "public": 2` when nothing was pending
+	flywayVersionPattern = regexp.MustCompile(`(?:now at version v|Current version of schema \S+: )(\d[\w.]*)`)
+)
+
+// ParseRevision reads Flyway's migrate log. The last file name is rebuilt
+// from Flyway's "<version> - <description>" using the V<version>__<desc>.sql
+// naming convention.
+func (e *flywayEngine) ParseRevision(output string) Revision {
+	matches := flywayAppliedPattern.FindAllStringSubmatch(output, -1)
+	rev := Revision{
+		Version: lastMatch(flywayVersionPattern, output),
+		Applied: len(matches),
+	}
+	if n := len(matches); n > 0 {
+		last := matches[n-1]
+		rev.LastFile = fmt.Sprintf("V%s__%s.sql", last[1], strings.ReplaceAll(last[2], " ", "_"))
+	}
+	return rev
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// Secret keys for goose's GOOSE_DRIVER / GOOSE_DBSTRING env vars
+const (
+	gooseDriverKey   = "goose-driver"
+	gooseDBStringKey = "goose-dbstring"
+)
+
+// gooseEngine runs `goose up`
+type gooseEngine struct{}
+
+func (e *gooseEngine) Name() dbupgradev1alpha1.MigrationEngineType {
+	return dbupgradev1alpha1.MigrationEngineGoose
+}
+
+// SecretData derives the goose driver from the URL scheme. Postgres URLs are
+// accepted as-is by pgx; MySQL needs the go-sql-driver DSN form.
+func (e *gooseEngine) SecretData(databaseURL string) (map[string][]byte, error) {
+	info, err := parseDatabaseURL(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dbString := databaseURL
+	if info.dialect == dialectMySQL {
+		dbString = info.mysqlDSN()
+	}
+	return map[string][]byte{
+		gooseDriverKey:   []byte(info.dialect),
+		gooseDBStringKey: []byte(dbString),
+	}, nil
+}
+
+// Container runs `goose up`, or `goose up-to <version>` when a target is set
+func (e *gooseEngine) Container(opts Options) corev1.Container {
+	if opts.TargetVersion != "" {
+		return e.container(opts, "up-to", opts.TargetVersion)
+	}
+	return e.container(opts, "up")
+}
+
+// DownContainer runs `goose down-to <version>`, which applies the
+// -- +goose Down sections of every version above the target
+func (e *gooseEngine) DownContainer(opts Options) corev1.Container {
+	return e.container(opts, "down-to", opts.TargetVersion)
+}
+
+func (e *gooseEngine) container(opts Options, command ...string) corev1.Container {
+	return corev1.Container{
+		Name:    ContainerName,
+		Image:   GooseImage,
+		Command: []string{"goose"},
+		Args:    append([]string{"-dir", migrationsPath(opts.Dir)}, command...),
+		Env: []corev1.EnvVar{
+			secretEnv("GOOSE_DRIVER", opts.SecretName, gooseDriverKey),
+			secretEnv("GOOSE_DBSTRING", opts.SecretName, gooseDBStringKey),
+		},
+		VolumeMounts: migrationsMount(),
+	}
+}
+
+// goosePendingPattern matches "    Pending                  -- 20240101000002_create_posts.sql"
+var goosePendingPattern = regexp.MustCompile(`(?m)^\s*Pending\s+--\s+(\S+)`)
+
+// PlanContainer runs `goose status`, which lists applied and pending files
+func (e *gooseEngine) PlanContainer(opts Options) corev1.Container {
+	return e.container(opts, "status")
+}
+
+func (e *gooseEngine) PendingMigrations(output string) []string {
+	return matchAll(goosePendingPattern, output)
+}
+
+var (
+	// gooseAppliedPattern matches "OK   20240101_create_users.sql (12.3ms)"
+	gooseAppliedPattern = regexp.MustCompile(`(?m)(?:^|\s)OK\s+(\S+)`)
+
+	// gooseVersionPattern matches the final "successfully migrated database to
+	// version: 20240102" or "no migrations to run. current version: 20240102"
+	gooseVersionPattern = regexp.MustCompile(`(?:to version|current version): (\d+)`)
+)
+
+func (e *gooseEngine) ParseRevision(output string) Revision {
+	applied := matchAll(gooseAppliedPattern, output)
+	rev := Revision{
+		Version: lastMatch(gooseVersionPattern, output),
+		Applied: len(applied),
+	}
+	if len(applied) > 0 {
+		rev.LastFile = applied[len(applied)-1]
+	}
+	return rev
+}
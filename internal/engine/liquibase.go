@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// liquibaseEngine runs `liquibase update`. Credentials are passed through
+// LIQUIBASE_COMMAND_* env vars, which Liquibase reads natively.
+type liquibaseEngine struct {
+	spec *dbupgradev1alpha1.LiquibaseSpec
+}
+
+func (e *liquibaseEngine) Name() dbupgradev1alpha1.MigrationEngineType {
+	return dbupgradev1alpha1.MigrationEngineLiquibase
+}
+
+func (e *liquibaseEngine) SecretData(databaseURL string) (map[string][]byte, error) {
+	return jdbcSecretData(databaseURL)
+}
+
+// Container runs `liquibase update`, or `liquibase update-to-tag` when a
+// target is set (Liquibase targets are tags, not changeset ids)
+func (e *liquibaseEngine) Container(opts Options) corev1.Container {
+	return e.container(opts, "update", "update-to-tag")
+}
+
+// DownContainer runs `liquibase rollback --tag=<version>`, which rolls back
+// every changeset applied after the tag
+func (e *liquibaseEngine) DownContainer(opts Options) corev1.Container {
+	return e.container(opts, "rollback", "rollback")
+}
+
+// container renders the runner for command, or toTagCommand when a target
+// version is set
+func (e *liquibaseEngine) container(opts Options, command, toTagCommand string) corev1.Container {
+	args := []string{
+		"--search-path=" + migrationsPath(opts.Dir),
+		"--changelog-file=" + e.spec.ChangeLogFile,
+	}
+	if opts.TargetVersion != "" {
+		args = append(args, toTagCommand, "--tag="+opts.TargetVersion)
+	} else {
+		args = append(args, command)
+	}
+
+	container := corev1.Container{
+		Name:    ContainerName,
+		Image:   LiquibaseImage,
+		Command: []string{"liquibase"},
+		Args:    args,
+		Env: []corev1.EnvVar{
+			secretEnv("LIQUIBASE_COMMAND_URL", opts.SecretName, jdbcURLKey),
+			secretEnv("LIQUIBASE_COMMAND_USERNAME", opts.SecretName, usernameKey),
+			secretEnv("LIQUIBASE_COMMAND_PASSWORD", opts.SecretName, passwordKey),
+		},
+		VolumeMounts: migrationsMount(),
+	}
+	if opts.ConnectionFile {
+		container.Args = append([]string{"--defaults-file=" + connectionFilePath(liquibaseConfigFile)}, container.Args...)
+		container.Env = nil
+		container.VolumeMounts = append(container.VolumeMounts, connectionMount())
+	}
+	return container
+}
+
+// liquibaseConfigFile holds the connection in File mode
+const liquibaseConfigFile = "liquibase.properties"
+
+// ConnectionFiles renders a Liquibase defaults file with the JDBC URL and credentials
+func (e *liquibaseEngine) ConnectionFiles(databaseURL string) (map[string][]byte, error) {
+	data, err := jdbcSecretData(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{liquibaseConfigFile: propertiesFile([][2]string{
+		{"url", string(data[jdbcURLKey])},
+		{"username", string(data[usernameKey])},
+		{"password", string(data[passwordKey])},
+	})}, nil
+}
+
+// liquibasePendingPattern matches "-- Changeset changelog.xml::1::author" in update-sql output
+var liquibasePendingPattern = regexp.MustCompile(`(?m)^-- Changeset (\S+)`)
+
+// PlanContainer runs `liquibase update-sql`, which prints the SQL without executing it
+func (e *liquibaseEngine) PlanContainer(opts Options) corev1.Container {
+	return e.container(opts, "update-sql", "update-to-tag-sql")
+}
+
+func (e *liquibaseEngine) PendingMigrations(output string) []string {
+	return matchAll(liquibasePendingPattern, output)
+}
+
+// liquibaseAppliedPattern matches "Running Changeset: changelog.xml::1::alice"
+var liquibaseAppliedPattern = regexp.MustCompile(`(?m)Running Changeset: (\S+)`)
+
+// ParseRevision counts the changesets `liquibase update` ran. Liquibase has no
+// linear version, so Version is left empty and the controller falls back to
+// the target tag.
+func (e *liquibaseEngine) ParseRevision(output string) Revision {
+	applied := matchAll(liquibaseAppliedPattern, output)
+	rev := Revision{Applied: len(applied)}
+	if len(applied) > 0 {
+		rev.LastFile = applied[len(applied)-1]
+	}
+	return rev
+}
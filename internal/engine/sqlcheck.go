@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+	"github.com/subganapathy/automatic-db-upgrades/internal/env"
+)
+
+// Client images for SQL checks. Override via env vars (Helm/Kustomize).
+var (
+	// PsqlImage provides psql for postgres SQL checks
+	PsqlImage = env.GetOrDefault("PSQL_IMAGE", "postgres:16-alpine")
+
+	// MySQLClientImage provides the mysql client for mysql and mariadb SQL checks
+	MySQLClientImage = env.GetOrDefault("MYSQL_CLIENT_IMAGE", "mysql:8.0")
+)
+
+// SQLCheckConfigKey is the key of the SQL check client's connection file in
+// the operator-managed Secret. Check containers see it under ConnectionMountPath.
+const SQLCheckConfigKey = "sql-check.conf"
+
+// pgService is the service name in the psql connection file
+const pgService = "dbupgrade"
+
+// libpqParams are the URL parameters passed on to psql; others (e.g.
+// x-migrations-table) are runner-specific and rejected by libpq
+var libpqParams = []string{"sslmode", "sslrootcert", "sslcert", "sslkey", "connect_timeout", "application_name", "target_session_attrs"}
+
+// mysqlSSLModes translates go-sql-driver's tls parameter to the mysql
+// client's --ssl-mode. The client does not read the system CA store, so
+// tls=true only requires encryption.
+var mysqlSSLModes = map[string]string{
+	"false":       "DISABLED",
+	"preferred":   "PREFERRED",
+	"skip-verify": "REQUIRED",
+	"true":        "REQUIRED",
+	"custom":      "VERIFY_IDENTITY",
+}
+
+// SQLCheckFiles renders the connection file of the SQL check client: a
+// pg_service.conf for postgres, a [client] option file for mysql
+func SQLCheckFiles(databaseURL string) (map[string][]byte, error) {
+	info, err := parseDatabaseURL(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	host, port, err := net.SplitHostPort(info.host)
+	if err != nil {
+		return nil, fmt.Errorf("database URL has no port")
+	}
+
+	var b strings.Builder
+	if info.dialect == dialectPostgres {
+		fmt.Fprintf(&b, "[%s]\nhost=%s\nport=%s\ndbname=%s\nuser=%s\n", pgService, host, port, info.database, info.username)
+		if info.password != "" {
+			if strings.ContainsAny(info.password, "\r\n") {
+				return nil, fmt.Errorf("password contains a line break, which is not supported by SQL checks")
+			}
+			fmt.Fprintf(&b, "password=%s\n", info.password)
+		}
+		for _, key := range libpqParams {
+			if v := info.query.Get(key); v != "" {
+				fmt.Fprintf(&b, "%s=%s\n", key, v)
+			}
+		}
+		return map[string][]byte{SQLCheckConfigKey: []byte(b.String())}, nil
+	}
+
+	fmt.Fprintf(&b, "[client]\nhost=%s\nport=%s\ndatabase=%s\nuser=%s\n", host, port, info.database, info.username)
+	if info.password != "" {
+		password, err := optionFileValue(info.password)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "password=%s\n", password)
+	}
+	if tls := info.query.Get("tls"); tls != "" {
+		mode, ok := mysqlSSLModes[tls]
+		if !ok {
+			return nil, fmt.Errorf("tls=%s is not supported by SQL checks", tls)
+		}
+		fmt.Fprintf(&b, "ssl-mode=%s\n", mode)
+		for _, p := range [][2]string{{"x-tls-ca", "ssl-ca"}, {"x-tls-cert", "ssl-cert"}, {"x-tls-key", "ssl-key"}} {
+			if v := info.query.Get(p[0]); v != "" && tls == "custom" {
+				fmt.Fprintf(&b, "%s=%s\n", p[1], v)
+			}
+		}
+	}
+	if info.query.Get("allowCleartextPasswords") == "true" {
+		b.WriteString("enable-cleartext-plugin\n")
+	}
+	return map[string][]byte{SQLCheckConfigKey: []byte(b.String())}, nil
+}
+
+// optionFileValue quotes a mysql option file value. Option files have no
+// escape for the quote character itself, so the other quote is used.
+func optionFileValue(v string) (string, error) {
+	v = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(v)
+	switch {
+	case !strings.Contains(v, `"`):
+		return `"` + v + `"`, nil
+	case !strings.Contains(v, "'"):
+		return "'" + v + "'", nil
+	}
+	return "", fmt.Errorf("password contains both quote characters, which is not supported by SQL checks")
+}
+
+// SQLCheckContainers renders one client container per check, named
+// check-<index>. Each prints the query result alone on stdout; the query runs
+// read-only and is cancelled after the check's timeout. Queries must be a
+// single statement, so they cannot turn read-only mode off before writing.
+//
+// On postgres the query runs in a READ ONLY transaction that is rolled back.
+// On mysql the session is read-only, but max_execution_time only applies to
+// SELECT statements; MariaDB's max_statement_time applies to all of them.
+func SQLCheckContainers(databaseURL string, checks []dbupgradev1alpha1.SQLCheck) ([]corev1.Container, error) {
+	info, err := parseDatabaseURL(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]corev1.Container, 0, len(checks))
+	for i, check := range checks {
+		query, err := singleStatement(check.Query)
+		if err != nil {
+			return nil, fmt.Errorf("SQL check %s: %w", check.Name, err)
+		}
+		timeout := check.TimeoutSeconds
+		if timeout <= 0 {
+			timeout = 30
+		}
+
+		container := corev1.Container{
+			Name:         SQLCheckContainerName(i),
+			VolumeMounts: []corev1.VolumeMount{connectionMount()},
+		}
+		switch info.dialect {
+		case dialectPostgres:
+			container.Image = PsqlImage
+			container.Command = []string{"psql"}
+			// Quiet mode keeps the BEGIN and ROLLBACK tags out of the output
+			container.Args = []string{"-X", "-q", "-A", "-t", "-v", "ON_ERROR_STOP=1", "-c", "BEGIN READ ONLY", "-c", query, "-c", "ROLLBACK"}
+			container.Env = []corev1.EnvVar{
+				{Name: "PGSERVICEFILE", Value: connectionFilePath(SQLCheckConfigKey)},
+				{Name: "PGSERVICE", Value: pgService},
+				{Name: "PGOPTIONS", Value: fmt.Sprintf("-c default_transaction_read_only=on -c statement_timeout=%d", int(timeout)*1000)},
+			}
+		default:
+			// MariaDB has max_statement_time (seconds) instead of max_execution_time
+			statementTimeout := "max_execution_time=" + strconv.Itoa(int(timeout)*1000)
+			if info.scheme == "maria" || info.scheme == "mariadb" {
+				statementTimeout = "max_statement_time=" + strconv.Itoa(int(timeout))
+			}
+			container.Image = MySQLClientImage
+			container.Command = []string{"mysql"}
+			container.Args = []string{
+				"--defaults-extra-file=" + connectionFilePath(SQLCheckConfigKey),
+				"--batch", "--skip-column-names",
+				"-e", fmt.Sprintf("SET SESSION TRANSACTION READ ONLY; SET SESSION %s; %s", statementTimeout, query),
+			}
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// singleStatement returns query without trailing semicolons, or an error if
+// it is more than one statement or a psql meta-command. Like the webhook, it
+// rejects semicolons in string literals too.
+func singleStatement(query string) (string, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	if strings.Contains(query, ";") {
+		return "", fmt.Errorf("query must be a single statement")
+	}
+	if strings.HasPrefix(query, "\\") {
+		return "", fmt.Errorf("query must be a SQL statement, not a client command")
+	}
+	return query, nil
+}
+
+// SQLCheckContainerName is the container running checks[index]
+func SQLCheckContainerName(index int) string {
+	return fmt.Sprintf("check-%d", index)
+}
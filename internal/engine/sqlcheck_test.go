@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
+)
+
+// TestSQLCheckFiles tests the client connection files for each dialect
+func TestSQLCheckFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "postgres keeps libpq params only",
+			url:      "postgres://app:p%40ss@db:5432/app?sslmode=verify-full&sslrootcert=/etc/dbupgrade/tls/tls-ca.crt&x-migrations-table=schema_migrations",
+			expected: "[dbupgrade]\nhost=db\nport=5432\ndbname=app\nuser=app\npassword=p@ss\nsslmode=verify-full\nsslrootcert=/etc/dbupgrade/tls/tls-ca.crt\n",
+		},
+		{
+			name:     "mysql custom tls",
+			url:      "mysql://app:s3cret@db:3306/app?tls=custom&x-tls-ca=/etc/dbupgrade/tls/tls-ca.crt&allowCleartextPasswords=true",
+			expected: "[client]\nhost=db\nport=3306\ndatabase=app\nuser=app\npassword=\"s3cret\"\nssl-mode=VERIFY_IDENTITY\nssl-ca=/etc/dbupgrade/tls/tls-ca.crt\nenable-cleartext-plugin\n",
+		},
+		{
+			name:     "mysql password with a double quote",
+			url:      "maria://app:a%22b%5C@db:3306/app?tls=skip-verify",
+			expected: "[client]\nhost=db\nport=3306\ndatabase=app\nuser=app\npassword='a\"b\\\\'\nssl-mode=REQUIRED\n",
+		},
+		{
+			name:    "mysql unsupported tls config",
+			url:     "mysql://app:s3cret@db:3306/app?tls=rds",
+			wantErr: true,
+		},
+		{
+			name:    "postgres password with a line break",
+			url:     "postgres://app:a%0Ab@db:5432/app",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := SQLCheckFiles(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("SQLCheckFiles() expected error, got %q", files[SQLCheckConfigKey])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SQLCheckFiles() error = %v", err)
+			}
+			if got := string(files[SQLCheckConfigKey]); got != tt.expected {
+				t.Errorf("%s = %q, expected %q", SQLCheckConfigKey, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSQLCheckContainers tests that each check runs read-only with its timeout
+func TestSQLCheckContainers(t *testing.T) {
+	checks := []dbupgradev1alpha1.SQLCheck{
+		{Name: "orders", Query: "SELECT count(*) FROM orders;", TimeoutSeconds: 10},
+		{Name: "legacy", Query: "SELECT count(*) = 0 FROM legacy_orders"},
+	}
+
+	containers, err := SQLCheckContainers("postgres://app:pass@db:5432/app", checks)
+	if err != nil {
+		t.Fatalf("SQLCheckContainers() error = %v", err)
+	}
+	if len(containers) != 2 || containers[1].Name != "check-1" || containers[1].Image != PsqlImage {
+		t.Fatalf("containers = %+v", containers)
+	}
+	expected := `-c BEGIN READ ONLY -c SELECT count(*) FROM orders -c ROLLBACK`
+	if args := strings.Join(containers[0].Args, " "); !strings.HasSuffix(args, expected) {
+		t.Errorf("psql args = %q, expected the query in a read-only transaction", args)
+	}
+	env := map[string]string{}
+	for _, e := range containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env["PGOPTIONS"] != "-c default_transaction_read_only=on -c statement_timeout=10000" {
+		t.Errorf("PGOPTIONS = %q", env["PGOPTIONS"])
+	}
+	if env["PGSERVICEFILE"] != "/etc/dbupgrade/connection/sql-check.conf" {
+		t.Errorf("PGSERVICEFILE = %q", env["PGSERVICEFILE"])
+	}
+
+	for scheme, timeout := range map[string]string{"mysql": "max_execution_time=30000", "maria": "max_statement_time=30"} {
+		containers, err := SQLCheckContainers(scheme+"://app:pass@db:3306/app", checks[1:])
+		if err != nil {
+			t.Fatalf("SQLCheckContainers(%s) error = %v", scheme, err)
+		}
+		script := containers[0].Args[len(containers[0].Args)-1]
+		expected := "SET SESSION TRANSACTION READ ONLY; SET SESSION " + timeout + "; " + checks[1].Query
+		if containers[0].Image != MySQLClientImage || script != expected {
+			t.Errorf("%s: image = %s, script = %q, expected %q", scheme, containers[0].Image, script, expected)
+		}
+		if !strings.HasPrefix(containers[0].Args[0], "--defaults-extra-file=") {
+			t.Errorf("%s: mysql args = %v, expected --defaults-extra-file first", scheme, containers[0].Args)
+		}
+	}
+
+	for _, query := range []string{"SET default_transaction_read_only = off; DELETE FROM orders", `\! rm -rf /`} {
+		if _, err := SQLCheckContainers("postgres://app:pass@db:5432/app", []dbupgradev1alpha1.SQLCheck{{Name: "unsafe", Query: query}}); err == nil {
+			t.Errorf("SQLCheckContainers(%q) expected an error", query)
+		}
+	}
+}
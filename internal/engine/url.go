@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Database dialects derived from the canonical connection URL scheme
+const (
+	dialectPostgres = "postgres"
+	dialectMySQL    = "mysql"
+)
+
+// connInfo is the parsed form of the canonical connection URL
+type connInfo struct {
+	dialect  string
+	scheme   string
+	username string
+	password string
+	host     string
+	database string
+	query    url.Values
+}
+
+// parseDatabaseURL parses a canonical postgres:// or mysql:// URL
+func parseDatabaseURL(raw string) (*connInfo, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		// Do not wrap err: url.Error embeds the full URL including the password
+		return nil, fmt.Errorf("database URL is not a valid URL")
+	}
+
+	var dialect string
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		dialect = dialectPostgres
+	case "mysql", "maria", "mariadb":
+		dialect = dialectMySQL
+	default:
+		return nil, fmt.Errorf("unsupported database URL scheme %q", u.Scheme)
+	}
+
+	info := &connInfo{
+		dialect:  dialect,
+		scheme:   u.Scheme,
+		host:     u.Host,
+		database: strings.TrimPrefix(u.Path, "/"),
+		query:    u.Query(),
+	}
+	if u.User != nil {
+		info.username = u.User.Username()
+		info.password, _ = u.User.Password()
+	}
+	return info, nil
+}
+
+// jdbcURL renders the URL without credentials in JDBC form (Flyway, Liquibase)
+func (c *connInfo) jdbcURL() string {
+	query := url.Values{}
+	for k, v := range c.query {
+		query[k] = v
+	}
+
+	sub := "postgresql"
+	if c.dialect == dialectMySQL {
+		sub = "mysql"
+		// go-sql-driver's tls=true maps to Connector/J's sslMode
+		if query.Get("tls") == "true" {
+			query.Del("tls")
+			query.Set("sslMode", "REQUIRED")
+		}
+		// Connector/J sends cleartext passwords over TLS without being asked
+		query.Del("allowCleartextPasswords")
+	}
+
+	out := fmt.Sprintf("jdbc:%s://%s/%s", sub, c.host, c.database)
+	if len(query) > 0 {
+		out += "?" + query.Encode()
+	}
+	return out
+}
+
+// mysqlDSN renders the go-sql-driver DSN (user:pass@tcp(host:port)/db?params)
+func (c *connInfo) mysqlDSN() string {
+	var creds string
+	if c.username != "" {
+		creds = c.username
+		if c.password != "" {
+			creds += ":" + c.password
+		}
+		creds += "@"
+	}
+
+	out := fmt.Sprintf("%stcp(%s)/%s", creds, c.host, c.database)
+	if len(c.query) > 0 {
+		out += "?" + c.query.Encode()
+	}
+	return out
+}
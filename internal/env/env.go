@@ -0,0 +1,14 @@
+// Package env reads the operator settings passed as environment variables
+// (Helm/Kustomize).
+package env
+
+import "os"
+
+// GetOrDefault returns the value of the environment variable key, or
+// defaultValue if it is unset or empty
+func GetOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
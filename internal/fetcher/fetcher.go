@@ -0,0 +1,318 @@
+// Package fetcher fills the migrations volume of a migration Job: it extracts
+// the migrations directory from an image or OCI artifact, or copies a mounted
+// ConfigMap or Secret, and records the fetched files in a manifest.
+package fetcher
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+)
+
+// Exit codes of the fetcher command. The controller maps them to
+// status.lastFailure.type, so they must not change.
+const (
+	ExitError        = 1
+	ExitUsage        = 2
+	ExitRegistryAuth = 3
+	ExitNotFound     = 4
+	ExitEmpty        = 5
+)
+
+// ErrEmpty is returned when the migrations directory is missing or has no files
+var ErrEmpty = errors.New("no migration files found")
+
+// ManifestFile is written at the root of the output directory. It records
+// what was fetched for anyone inspecting the migrations volume; the runners
+// do not read it.
+const ManifestFile = ".dbupgrade-manifest.json"
+
+// Layer annotations oras push sets on the files of an artifact
+const (
+	titleAnnotation  = "org.opencontainers.image.title"
+	unpackAnnotation = "io.deis.oras.content.unpack"
+)
+
+// Manifest lists the fetched migration files
+type Manifest struct {
+	// Source is the image or directory the files came from
+	Source string `json:"source"`
+	// Digest is the platform manifest digest of an image or artifact source
+	Digest string `json:"digest,omitempty"`
+	// Dir is the migrations directory, relative to the output directory
+	Dir string `json:"dir"`
+	// Files are sorted by path
+	Files []File `json:"files"`
+}
+
+// File is one migration file
+type File struct {
+	// Path is relative to Dir, with forward slashes
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ExitCode maps an error of the fetcher to its exit code
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, registry.ErrUnauthorized):
+		return ExitRegistryAuth
+	case errors.Is(err, registry.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, ErrEmpty):
+		return ExitEmpty
+	}
+	return ExitError
+}
+
+// CleanDir turns migrations.dir into a clean absolute path
+func CleanDir(dir string) string {
+	return path.Clean("/" + dir)
+}
+
+// ExtractImage writes the files under dir in img's filesystem to
+// output/dir. The filesystem is flattened by go-containerregistry, which
+// applies the layers and their file whiteouts. Only regular files and
+// directories are supported.
+func ExtractImage(img *registry.Image, dir, output string) error {
+	dir = CleanDir(dir)
+	rc := img.Extract()
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read image %s: %w", img.Digest, err)
+		}
+		// Rooting the name before cleaning keeps ../ from escaping output
+		name := path.Clean("/" + hdr.Name)
+		if !within(name, dir) {
+			continue
+		}
+		if err := writeEntry(tr, hdr, name, output); err != nil {
+			return err
+		}
+	}
+}
+
+// ExtractArtifact writes the files of an OCI artifact pushed with oras to
+// output/dir, as oras pull --output would: each layer is a file named by its
+// title annotation, or a directory packed as a tar.gz.
+func ExtractArtifact(img *registry.Image, dir, output string) error {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("failed to read manifest of %s: %w", img.Digest, err)
+	}
+	target := filepath.Join(output, filepath.FromSlash(CleanDir(dir)))
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[titleAnnotation]
+		if title == "" {
+			continue
+		}
+		title = path.Clean("/" + title)
+		if err := extractBlob(img, layer.Digest, title, layer.Annotations[unpackAnnotation] == "true", target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractBlob writes the blob digest to target/title, or unpacks it there if
+// it is a directory
+func extractBlob(img *registry.Image, digest v1.Hash, title string, unpack bool, target string) error {
+	rc, err := img.OpenBlob(digest)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if !unpack {
+		err = writeFile(filepath.Join(target, filepath.FromSlash(title)), rc)
+	} else {
+		err = unpackDir(rc, title, target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", strings.TrimPrefix(title, "/"), err)
+	}
+	// Read to EOF so the blob digest is checked
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		return fmt.Errorf("failed to read blob %s: %w", digest, err)
+	}
+	return nil
+}
+
+// unpackDir extracts a directory packed by oras, whose entries are rooted at
+// the directory name
+func unpackDir(r io.Reader, dir, target string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean("/" + hdr.Name)
+		if !within(name, dir) {
+			return fmt.Errorf("%s is outside of the directory", name)
+		}
+		if err := writeEntry(tr, hdr, name, target); err != nil {
+			return err
+		}
+	}
+}
+
+// writeEntry writes the tar entry hdr to output/name
+func writeEntry(tr *tar.Reader, hdr *tar.Header, name, output string) error {
+	target := filepath.Join(output, filepath.FromSlash(name))
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, 0755)
+	case tar.TypeReg:
+		return writeFile(target, tr)
+	case tar.TypeSymlink, tar.TypeLink:
+		return fmt.Errorf("%s is a link, which is not supported in the migrations directory", name)
+	}
+	return nil
+}
+
+// within reports whether name is dir or below it
+func within(name, dir string) bool {
+	return dir == "/" || name == dir || strings.HasPrefix(name, dir+"/")
+}
+
+// CopyDir copies the regular files at the top of source to output/dir.
+// Hidden entries are skipped: ConfigMap and Secret volumes keep their
+// payload in ..data directories behind symlinks, which are followed.
+func CopyDir(source, dir, output string) error {
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	target := filepath.Join(output, filepath.FromSlash(CleanDir(dir)))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		f, err := os.Open(filepath.Join(source, entry.Name()))
+		if err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err == nil && info.Mode().IsRegular() {
+			err = writeFile(filepath.Join(target, entry.Name()), f)
+		}
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes r to name, world-readable so runners with another UID
+// can read it
+func writeFile(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return f.Close()
+}
+
+// BuildManifest lists and checksums the files under output/dir. It returns
+// ErrEmpty if there are none.
+func BuildManifest(output, dir string) (*Manifest, error) {
+	dir = CleanDir(dir)
+	root := filepath.Join(output, filepath.FromSlash(dir))
+	manifest := &Manifest{Dir: strings.TrimPrefix(dir, "/")}
+
+	err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() || name == filepath.Join(output, ManifestFile) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		file, err := checksum(name)
+		if err != nil {
+			return err
+		}
+		file.Path = filepath.ToSlash(rel)
+		manifest.Files = append(manifest.Files, file)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: directory %s does not exist", ErrEmpty, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("%w: directory %s is empty", ErrEmpty, dir)
+	}
+
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+	return manifest, nil
+}
+
+func checksum(name string) (File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// WriteManifest writes manifest to output/ManifestFile
+func WriteManifest(output string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(output, ManifestFile), append(data, '\n'), 0644)
+}
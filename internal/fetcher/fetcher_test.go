@@ -0,0 +1,326 @@
+package fetcher
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+)
+
+// layerEntry is a file (content != ""), directory (name ending in /) or
+// whiteout of a test layer
+type layerEntry struct {
+	name    string
+	content string
+}
+
+func gzipLayer(t *testing.T, entries ...layerEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0600, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		if strings.HasSuffix(e.name, "/") {
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0700
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// descriptor describes data as a manifest, config or layer of mediaType
+func descriptor(mediaType string, data []byte, annotations map[string]string) map[string]interface{} {
+	d := map[string]interface{}{"mediaType": mediaType, "digest": digestOf(data), "size": len(data)}
+	if annotations != nil {
+		d["annotations"] = annotations
+	}
+	return d
+}
+
+// serve serves manifests and blobs by digest over plain HTTP, with the first
+// manifest also tagged v2, and returns the reference of the tag. Manifests
+// are served with the media type they declare.
+func serve(t *testing.T, manifests [][]byte, blobs ...[]byte) string {
+	t.Helper()
+	byDigest := map[string][]byte{}
+	for _, data := range append(append([][]byte{}, manifests...), blobs...) {
+		byDigest[digestOf(data)] = data
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/v2/acme/app/")
+		kind, ref, _ := strings.Cut(path, "/")
+		data, ok := byDigest[ref]
+		if kind == "manifests" && ref == "v2" {
+			data, ok = manifests[0], true
+		}
+		if !ok || (kind != "manifests" && kind != "blobs") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if kind == "manifests" {
+			var doc struct {
+				MediaType string `json:"mediaType"`
+			}
+			_ = json.Unmarshal(data, &doc)
+			w.Header().Set("Content-Type", doc.MediaType)
+			w.Header().Set("Docker-Content-Digest", digestOf(data))
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://") + "/acme/app:v2"
+}
+
+// serveImage serves a multi-arch index with a linux/amd64 image of layers
+func serveImage(t *testing.T, layers ...[]byte) string {
+	t.Helper()
+	config := []byte(`{"architecture":"amd64","os":"linux"}`)
+	var descriptors []map[string]interface{}
+	for _, layer := range layers {
+		descriptors = append(descriptors, descriptor("application/vnd.oci.image.layer.v1.tar+gzip", layer, nil))
+	}
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        descriptor("application/vnd.oci.image.config.v1+json", config, nil),
+		"layers":        descriptors,
+	})
+	platform := descriptor("application/vnd.oci.image.manifest.v1+json", manifest, nil)
+	platform["platform"] = map[string]string{"os": "linux", "architecture": "amd64"}
+	index, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests":     []map[string]interface{}{platform},
+	})
+	return serve(t, [][]byte{index, manifest}, append([][]byte{config}, layers...)...)
+}
+
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		rel, _ := filepath.Rel(root, name)
+		files[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// TestExtractImage tests that layers are applied with their file whiteouts
+// and only the migrations directory is extracted
+func TestExtractImage(t *testing.T) {
+	base := gzipLayer(t,
+		layerEntry{name: "app/", content: ""},
+		layerEntry{name: "app/server", content: "binary"},
+		layerEntry{name: "db/migrations/", content: ""},
+		layerEntry{name: "db/migrations/001_init.sql", content: "CREATE TABLE users (id int);"},
+		layerEntry{name: "db/migrations/002_old.sql", content: "DROP TABLE legacy;"},
+		layerEntry{name: "db/migrations/seed/", content: ""},
+		layerEntry{name: "db/migrations/seed/users.sql", content: "INSERT INTO users VALUES (1);"},
+		layerEntry{name: "../../etc/passwd", content: "root"},
+	)
+	upper := gzipLayer(t,
+		layerEntry{name: "db/migrations/001_init.sql", content: "CREATE TABLE users (id bigint);"},
+		layerEntry{name: "db/migrations/.wh.002_old.sql"},
+		layerEntry{name: "db/migrations/seed/.wh.users.sql"},
+		layerEntry{name: "db/migrations/seed/accounts.sql", content: "INSERT INTO accounts VALUES (1);"},
+		layerEntry{name: "db/migrations/003_orders.sql", content: "CREATE TABLE orders (id int);"},
+	)
+	image := serveImage(t, base, upper)
+
+	ctx := context.Background()
+	img, err := registry.NewClient(true).Pull(ctx, image, registry.Platform{OS: "linux", Architecture: "amd64"}, nil)
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+
+	output := t.TempDir()
+	if err := ExtractImage(img, "db/migrations", output); err != nil {
+		t.Fatalf("ExtractImage() error = %v", err)
+	}
+	expected := map[string]string{
+		"db/migrations/001_init.sql":      "CREATE TABLE users (id bigint);",
+		"db/migrations/003_orders.sql":    "CREATE TABLE orders (id int);",
+		"db/migrations/seed/accounts.sql": "INSERT INTO accounts VALUES (1);",
+	}
+	if got := readTree(t, output); !reflect.DeepEqual(got, expected) {
+		t.Errorf("extracted files = %v, expected %v", got, expected)
+	}
+
+	manifest, err := BuildManifest(output, "db/migrations")
+	if err != nil {
+		t.Fatalf("BuildManifest() error = %v", err)
+	}
+	var paths []string
+	for _, f := range manifest.Files {
+		paths = append(paths, f.Path)
+	}
+	if strings.Join(paths, ",") != "001_init.sql,003_orders.sql,seed/accounts.sql" {
+		t.Errorf("manifest paths = %v", paths)
+	}
+	if manifest.Dir != "db/migrations" || manifest.Files[0].SHA256 != strings.TrimPrefix(digestOf([]byte(expected["db/migrations/001_init.sql"])), "sha256:") {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	_, err = registry.NewClient(true).Pull(ctx, image, registry.Platform{OS: "linux", Architecture: "arm64"}, nil)
+	if ExitCode(err) != ExitNotFound {
+		t.Errorf("Pull() of a missing platform error = %v, expected exit code %d", err, ExitNotFound)
+	}
+
+	empty := t.TempDir()
+	if err := ExtractImage(img, "/sql", empty); err != nil {
+		t.Fatalf("ExtractImage() error = %v", err)
+	}
+	if _, err := BuildManifest(empty, "/sql"); ExitCode(err) != ExitEmpty {
+		t.Errorf("BuildManifest() of a missing directory error = %v, expected exit code %d", err, ExitEmpty)
+	}
+}
+
+// TestExtractArtifact tests writing the files and directories of an artifact
+// pushed with oras
+func TestExtractArtifact(t *testing.T) {
+	initSQL := []byte("CREATE TABLE users (id int);")
+	seed := gzipLayer(t,
+		layerEntry{name: "seed/", content: ""},
+		layerEntry{name: "seed/users.sql", content: "INSERT INTO users VALUES (1);"},
+	)
+	escape := []byte("DROP TABLE users;")
+	config := []byte("{}")
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        descriptor("application/vnd.unknown.config.v1+json", config, nil),
+		"layers": []map[string]interface{}{
+			descriptor("application/vnd.oci.image.layer.v1.tar", initSQL, map[string]string{titleAnnotation: "001_init.sql"}),
+			descriptor("application/vnd.oci.image.layer.v1.tar+gzip", seed, map[string]string{titleAnnotation: "seed", unpackAnnotation: "true"}),
+			descriptor("application/vnd.oci.image.layer.v1.tar", escape, map[string]string{titleAnnotation: "../../002_escape.sql"}),
+		},
+	})
+	artifact := serve(t, [][]byte{manifest}, config, initSQL, seed, escape)
+
+	ctx := context.Background()
+	img, err := registry.NewClient(true).PullArtifact(ctx, artifact, nil)
+	if err != nil {
+		t.Fatalf("PullArtifact() error = %v", err)
+	}
+	if img.Digest != digestOf(manifest) {
+		t.Errorf("Digest = %s, expected %s", img.Digest, digestOf(manifest))
+	}
+
+	output := t.TempDir()
+	if err := ExtractArtifact(img, "/sql", output); err != nil {
+		t.Fatalf("ExtractArtifact() error = %v", err)
+	}
+	expected := map[string]string{
+		"sql/001_init.sql":   string(initSQL),
+		"sql/002_escape.sql": string(escape),
+		"sql/seed/users.sql": "INSERT INTO users VALUES (1);",
+	}
+	if got := readTree(t, output); !reflect.DeepEqual(got, expected) {
+		t.Errorf("extracted files = %v, expected %v", got, expected)
+	}
+
+	_, err = registry.NewClient(true).PullArtifact(ctx, strings.Replace(artifact, ":v2", ":v3", 1), nil)
+	if ExitCode(err) != ExitNotFound {
+		t.Errorf("PullArtifact() of a missing tag error = %v, expected exit code %d", err, ExitNotFound)
+	}
+}
+
+// TestCopyDir tests copying a ConfigMap volume without its hidden payload directory
+func TestCopyDir(t *testing.T) {
+	source := t.TempDir()
+	data := filepath.Join(source, "..2024_01_01_00_00_00.000")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "001_init.sql"), []byte("CREATE TABLE users (id int);"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Base(data), filepath.Join(source, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "001_init.sql"), filepath.Join(source, "001_init.sql")); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	if err := CopyDir(source, "/migrations", output); err != nil {
+		t.Fatalf("CopyDir() error = %v", err)
+	}
+	expected := map[string]string{"migrations/001_init.sql": "CREATE TABLE users (id int);"}
+	if got := readTree(t, output); !reflect.DeepEqual(got, expected) {
+		t.Errorf("copied files = %v, expected %v", got, expected)
+	}
+
+	manifest, err := BuildManifest(output, "/migrations")
+	if err != nil {
+		t.Fatalf("BuildManifest() error = %v", err)
+	}
+	if err := WriteManifest(output, manifest); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, ManifestFile)); err != nil {
+		t.Errorf("manifest was not written: %v", err)
+	}
+}
+
+// TestExitCode tests the exit codes the controller maps to failure types
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{err: nil, expected: 0},
+		{err: fmt.Errorf("failed to fetch manifest: %w", registry.ErrUnauthorized), expected: ExitRegistryAuth},
+		{err: fmt.Errorf("failed to fetch manifest: %w", registry.ErrNotFound), expected: ExitNotFound},
+		{err: fmt.Errorf("%w: directory /migrations is empty", ErrEmpty), expected: ExitEmpty},
+		{err: errors.New("disk full"), expected: ExitError},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.expected {
+			t.Errorf("ExitCode(%v) = %d, expected %d", tt.err, got, tt.expected)
+		}
+	}
+}
@@ -16,11 +16,36 @@ var (
 			Help: "Indicates if the DBUpgrade operator is running (always 1 when process is alive)",
 		},
 	)
+
+	// AWSCredentialsCacheHits counts AWS credential requests (RDS IAM tokens,
+	// Secrets Manager reads, ECR tokens) served from cached AssumeRole credentials
+	AWSCredentialsCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "dbupgrade_aws_credentials_cache_hits_total",
+			Help: "Number of AWS credential requests that reused cached AssumeRole credentials",
+		},
+	)
+
+	// AWSCredentialsCacheMisses counts AWS credential requests that had to call STS
+	AWSCredentialsCacheMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "dbupgrade_aws_credentials_cache_misses_total",
+			Help: "Number of AWS credential requests that called STS AssumeRole",
+		},
+	)
+
+	// AWSSTSErrors counts failed STS AssumeRole calls (including throttling)
+	AWSSTSErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "dbupgrade_aws_sts_errors_total",
+			Help: "Number of failed STS AssumeRole calls",
+		},
+	)
 )
 
 func init() {
 	// Register custom metrics with controller-runtime's metrics registry
-	metrics.Registry.MustRegister(OperatorUp)
+	metrics.Registry.MustRegister(OperatorUp, AWSCredentialsCacheHits, AWSCredentialsCacheMisses, AWSSTSErrors)
 }
 
 // SetOperatorUp sets the operator up metric to 1 to indicate the process is alive.
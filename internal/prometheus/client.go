@@ -0,0 +1,240 @@
+// Package prometheus runs instant PromQL queries against a Prometheus-compatible
+// HTTP API (Prometheus, Thanos, Mimir, VictoriaMetrics) for metric checks.
+package prometheus
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseBytes caps how much of a query response is read
+const maxResponseBytes = 10 << 20
+
+// maxTLSClients bounds the clients kept for custom TLS. Rotated certificates
+// leave unused clients behind, so all are dropped when the limit is reached.
+const maxTLSClients = 64
+
+// Client queries Prometheus servers. Servers without custom TLS share a
+// connection pool, and servers with the same TLS material share another; it
+// should be created once at startup and reused across reconciles.
+type Client struct {
+	httpClient *http.Client
+
+	mu sync.Mutex
+	// tlsClients are keyed by tlsKey of their TLS material
+	tlsClients map[[sha256.Size]byte]*http.Client
+}
+
+// NewClient creates a new Prometheus client with connection pooling.
+// Redirects are not followed, so an allowed server cannot send the operator
+// to another host.
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 20,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			Timeout:       30 * time.Second,
+			CheckRedirect: noRedirects,
+		},
+		tlsClients: map[[sha256.Size]byte]*http.Client{},
+	}
+}
+
+// Config identifies a server and the query to run
+type Config struct {
+	// URL is the server URL, without the /api/v1 path
+	URL string
+	// Query is an instant PromQL query
+	Query string
+	// BearerToken is sent in the Authorization header if set
+	BearerToken string
+	// TLS is the optional server verification and client certificate
+	TLS *TLSConfig
+}
+
+// TLSConfig holds PEM material read from the DBUpgrade's Secrets
+type TLSConfig struct {
+	CA         []byte
+	ClientCert []byte
+	ClientKey  []byte
+	// InsecureSkipVerify disables server certificate verification
+	InsecureSkipVerify bool
+}
+
+// Query runs an instant query and returns one value per series of a vector
+// result, or the value of a scalar result. An empty vector returns no values.
+func (c *Client) Query(ctx context.Context, cfg Config) ([]float64, error) {
+	httpClient, err := c.clientFor(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	u := strings.TrimSuffix(cfg.URL, "/") + "/api/v1/query?" + url.Values{"query": {cfg.Query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.BearerToken)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// Errors come back as JSON with 400, 422 and 503; proxies may send anything
+	var out struct {
+		Status    string `json:"status"`
+		ErrorType string `json:"errorType"`
+		Error     string `json:"error"`
+		Data      struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		if resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("query failed: %s", resp.Status)
+		}
+		return nil, fmt.Errorf("invalid query response: %w", err)
+	}
+	if out.Status != "success" {
+		if out.Error != "" {
+			return nil, fmt.Errorf("query failed: %s: %s", out.ErrorType, out.Error)
+		}
+		return nil, fmt.Errorf("query failed: %s", resp.Status)
+	}
+
+	switch out.Data.ResultType {
+	case "vector":
+		var series []struct {
+			Value sample `json:"value"`
+		}
+		if err := json.Unmarshal(out.Data.Result, &series); err != nil {
+			return nil, fmt.Errorf("invalid vector result: %w", err)
+		}
+		values := make([]float64, 0, len(series))
+		for _, s := range series {
+			values = append(values, float64(s.Value))
+		}
+		return values, nil
+	case "scalar":
+		var s sample
+		if err := json.Unmarshal(out.Data.Result, &s); err != nil {
+			return nil, fmt.Errorf("invalid scalar result: %w", err)
+		}
+		return []float64{float64(s)}, nil
+	}
+	return nil, fmt.Errorf("query returned a %s, expected an instant vector or scalar", out.Data.ResultType)
+}
+
+// sample decodes a [timestamp, "value"] pair to its value
+type sample float64
+
+func (s *sample) UnmarshalJSON(data []byte) error {
+	var pair []interface{}
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("sample has %d elements, expected 2", len(pair))
+	}
+	str, ok := pair[1].(string)
+	if !ok {
+		return fmt.Errorf("sample value is not a string")
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return fmt.Errorf("invalid sample value %q", str)
+	}
+	*s = sample(v)
+	return nil
+}
+
+// clientFor returns the pooled client, or the one for the custom TLS of cfg
+func (c *Client) clientFor(cfg *TLSConfig) (*http.Client, error) {
+	if cfg == nil {
+		return c.httpClient, nil
+	}
+
+	key := tlsKey(cfg)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if httpClient, ok := c.tlsClients[key]; ok {
+		return httpClient, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.InsecureSkipVerify} //nolint:gosec // opt-in
+	if len(cfg.CA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(cfg.CA) {
+			return nil, fmt.Errorf("CA bundle contains no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(cfg.ClientCert) > 0 {
+		cert, err := tls.X509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := c.httpClient.Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	httpClient := &http.Client{Transport: transport, Timeout: c.httpClient.Timeout, CheckRedirect: noRedirects}
+
+	if len(c.tlsClients) >= maxTLSClients {
+		for _, stale := range c.tlsClients {
+			stale.CloseIdleConnections()
+		}
+		c.tlsClients = map[[sha256.Size]byte]*http.Client{}
+	}
+	c.tlsClients[key] = httpClient
+	return httpClient, nil
+}
+
+// noRedirects makes a client return redirects as responses
+func noRedirects(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// tlsKey hashes the TLS material of cfg, length-prefixing each part so
+// different splits of the same bytes do not collide
+func tlsKey(cfg *TLSConfig) [sha256.Size]byte {
+	h := sha256.New()
+	for _, part := range [][]byte{cfg.CA, cfg.ClientCert, cfg.ClientKey} {
+		_ = binary.Write(h, binary.BigEndian, uint64(len(part)))
+		h.Write(part)
+	}
+	if cfg.InsecureSkipVerify {
+		h.Write([]byte{1})
+	}
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
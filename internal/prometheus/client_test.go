@@ -0,0 +1,132 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newStandInPrometheus serves /api/v1/query with canned responses keyed by
+// query, requiring the bearer token if one is given
+func newStandInPrometheus(t *testing.T, token string, tlsServer bool) *httptest.Server {
+	t.Helper()
+	responses := map[string]string{
+		`sum(rate(http_requests_total{code=~"5.."}[5m]))`: `{"status":"success","data":{"resultType":"vector","result":[` +
+			`{"metric":{"pod":"app-1"},"value":[1700000000,"0.5"]},{"metric":{"pod":"app-2"},"value":[1700000000,"1.25"]}]}}`,
+		`scalar(up)`:   `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`,
+		`absent_thing`: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		`up[5m]`:       `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prometheus/api/v1/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("Unauthorized\n"))
+			return
+		}
+		body, ok := responses[r.URL.Query().Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	})
+	if tlsServer {
+		return httptest.NewTLSServer(handler)
+	}
+	return httptest.NewServer(handler)
+}
+
+// TestQuery tests vector and scalar results, and query errors
+func TestQuery(t *testing.T) {
+	srv := newStandInPrometheus(t, "", false)
+	defer srv.Close()
+
+	tests := []struct {
+		query    string
+		expected []float64
+		errMsg   string
+	}{
+		{query: `sum(rate(http_requests_total{code=~"5.."}[5m]))`, expected: []float64{0.5, 1.25}},
+		{query: `scalar(up)`, expected: []float64{1}},
+		{query: `absent_thing`, expected: []float64{}},
+		{query: `up[5m]`, errMsg: "returned a matrix"},
+		{query: `rate(`, errMsg: "bad_data: parse error"},
+	}
+
+	c := NewClient()
+	for _, tt := range tests {
+		values, err := c.Query(context.Background(), Config{URL: srv.URL + "/prometheus/", Query: tt.query})
+		if tt.errMsg != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Query(%s) error = %v, expected %q", tt.query, err, tt.errMsg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Query(%s) error = %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("Query(%s) = %v, expected %v", tt.query, values, tt.expected)
+		}
+	}
+}
+
+// TestQueryRedirect tests that redirects are returned, not followed
+func TestQueryRedirect(t *testing.T) {
+	srv := newStandInPrometheus(t, "", false)
+	defer srv.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(srv.URL+"/prometheus/api/v1/query?query=scalar(up)", http.StatusFound))
+	defer redirect.Close()
+
+	_, err := NewClient().Query(context.Background(), Config{URL: redirect.URL, Query: "scalar(up)"})
+	if err == nil || !strings.Contains(err.Error(), "302 Found") {
+		t.Errorf("Query() error = %v, expected 302", err)
+	}
+}
+
+// TestQueryAuth tests the bearer token and a CA bundle for a private server
+func TestQueryAuth(t *testing.T) {
+	srv := newStandInPrometheus(t, "s3cret", true)
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	c := NewClient()
+	cfg := Config{URL: srv.URL + "/prometheus", Query: "scalar(up)", TLS: &TLSConfig{CA: ca}}
+	if _, err := c.Query(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Errorf("Query() without token error = %v, expected 401", err)
+	}
+
+	cfg.BearerToken = "s3cret"
+	if values, err := c.Query(context.Background(), cfg); err != nil || len(values) != 1 {
+		t.Errorf("Query() = %v, %v", values, err)
+	}
+
+	// The same TLS material reuses one client and its connections
+	first, _ := c.clientFor(&TLSConfig{CA: ca})
+	if second, _ := c.clientFor(&TLSConfig{CA: append([]byte{}, ca...)}); second != first || len(c.tlsClients) != 1 {
+		t.Errorf("clientFor() created %d clients for one CA bundle, expected 1", len(c.tlsClients))
+	}
+	if other, _ := c.clientFor(&TLSConfig{CA: ca, InsecureSkipVerify: true}); other == first {
+		t.Error("clientFor() reused the client for other TLS settings")
+	}
+
+	cfg.TLS = nil
+	if _, err := c.Query(context.Background(), cfg); err == nil {
+		t.Error("Query() expected error for an untrusted server certificate")
+	}
+
+	cfg.TLS = &TLSConfig{CA: []byte("not a certificate")}
+	if _, err := c.Query(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("Query() with an invalid CA error = %v", err)
+	}
+}
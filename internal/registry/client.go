@@ -0,0 +1,192 @@
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Client talks to OCI distribution registries with a shared connection pool.
+// It should be created once at startup and reused across reconciles.
+type Client struct {
+	transport http.RoundTripper
+	// insecure allows plain HTTP and unverified TLS (local registries only)
+	insecure bool
+}
+
+// NewClient creates a new registry client. insecure mirrors docker's
+// insecure-registries setting.
+func NewClient(insecure bool) *Client {
+	t := remote.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 20
+	if insecure {
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // opt-in for local registries
+	}
+	return &Client{transport: t, insecure: insecure}
+}
+
+// Credentials are docker login credentials for a registry
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Keychain maps registry hosts to credentials. It implements authn.Keychain;
+// registries without an entry are accessed anonymously.
+type Keychain map[string]Credentials
+
+// Resolve returns the authenticator for the registry of target
+func (k Keychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	creds, ok := k[normalizeHost(target.RegistryStr())]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(authn.AuthConfig{Username: creds.Username, Password: creds.Password}), nil
+}
+
+// ParseDockerConfig reads the auths of a docker config.json
+func ParseDockerConfig(data []byte) (Keychain, error) {
+	var config struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid docker config: %w", err)
+	}
+
+	keychain := Keychain{}
+	for key, entry := range config.Auths {
+		creds := Credentials{Username: entry.Username, Password: entry.Password}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth for registry %s", key)
+			}
+			creds.Username, creds.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		keychain[normalizeHost(key)] = creds
+	}
+	return keychain, nil
+}
+
+// normalizeHost reduces a docker config key (https://index.docker.io/v1/,
+// ghcr.io) to the registry host used in references
+func normalizeHost(key string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "docker.io", "registry-1.docker.io":
+		return DockerHub
+	}
+	return host
+}
+
+// Resolve returns the manifest digest image currently points to
+func (c *Client) Resolve(ctx context.Context, image string, keychain authn.Keychain) (string, error) {
+	ref, err := c.parse(image)
+	if err != nil {
+		return "", err
+	}
+	if digest, ok := ref.(name.Digest); ok {
+		return digest.DigestStr(), nil
+	}
+
+	if desc, err := remote.Head(ref, c.options(ctx, keychain)...); err == nil {
+		return desc.Digest.String(), nil
+	}
+	// Some registries only return the digest header on GET
+	desc, err := c.get(ctx, ref, keychain)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", image, classify(err))
+	}
+	return desc.Digest.String(), nil
+}
+
+// get fetches the descriptor of ref without following an index
+func (c *Client) get(ctx context.Context, ref name.Reference, keychain authn.Keychain) (*remote.Descriptor, error) {
+	return remote.Get(ref, c.options(ctx, keychain)...)
+}
+
+// parse parses image with ParseReference's validation into a reference the
+// remote package accepts
+func (c *Client) parse(image string) (name.Reference, error) {
+	if _, err := ParseReference(image); err != nil {
+		return nil, err
+	}
+	var opts []name.Option
+	if c.insecure {
+		opts = append(opts, name.Insecure)
+	}
+	// A tag next to the digest is informational; pull by digest
+	if before, digest, ok := strings.Cut(image, "@"); ok {
+		image = PinDigest(before, digest)
+	}
+	ref, err := name.ParseReference(image, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image %s: %w", image, err)
+	}
+	return ref, nil
+}
+
+// options are the remote options of one request
+func (c *Client) options(ctx context.Context, keychain authn.Keychain) []remote.Option {
+	if keychain == nil {
+		keychain = Keychain{}
+	}
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithTransport(c.transport),
+		remote.WithAuthFromKeychain(keychain),
+	}
+}
+
+// Errors a registry failure can be matched against with errors.Is
+var (
+	// ErrUnauthorized - the registry rejected the credentials, or needs some
+	ErrUnauthorized = errors.New("registry access denied")
+	// ErrNotFound - the repository, tag, platform or blob does not exist
+	ErrNotFound = errors.New("not found in registry")
+)
+
+// statusError is a failed registry response
+type statusError struct {
+	err  error
+	code int
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+
+func (e *statusError) Unwrap() error { return e.err }
+
+func (e *statusError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.code == http.StatusUnauthorized || e.code == http.StatusForbidden
+	case ErrNotFound:
+		return e.code == http.StatusNotFound
+	}
+	return false
+}
+
+// classify wraps registry responses so they match ErrUnauthorized and
+// ErrNotFound. Other errors are returned unchanged.
+func classify(err error) error {
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		return &statusError{err: err, code: terr.StatusCode}
+	}
+	return err
+}
@@ -0,0 +1,255 @@
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestParseReference tests registry, repository, tag and digest parsing
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		image    string
+		expected Reference
+		wantErr  bool
+	}{
+		{image: "postgres", expected: Reference{Registry: DockerHub, Repository: "library/postgres", Tag: "latest"}},
+		{image: "acme/migrations:v2", expected: Reference{Registry: DockerHub, Repository: "acme/migrations", Tag: "v2"}},
+		{image: "ghcr.io/acme/migrations:v2", expected: Reference{Registry: "ghcr.io", Repository: "acme/migrations", Tag: "v2"}},
+		{image: "registry.local:5000/migrations", expected: Reference{Registry: "registry.local:5000", Repository: "migrations", Tag: "latest"}},
+		{image: "ghcr.io/acme/migrations:v2@" + digest, expected: Reference{Registry: "ghcr.io", Repository: "acme/migrations", Tag: "v2", Digest: digest}},
+		{image: "ghcr.io/acme/migrations@sha256:abc", wantErr: true},
+		{image: "ghcr.io/Acme/migrations:v2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := ParseReference(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseReference() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPinDigest tests that pinning keeps the image name as written
+func TestPinDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("b", 64)
+	tests := map[string]string{
+		"postgres:16":                     "postgres@" + digest,
+		"registry.local:5000/migrations":  "registry.local:5000/migrations@" + digest,
+		"ghcr.io/acme/migrations:v2@sha1": "ghcr.io/acme/migrations@" + digest,
+	}
+	for image, expected := range tests {
+		if got := PinDigest(image, digest); got != expected {
+			t.Errorf("PinDigest(%q) = %s, expected %s", image, got, expected)
+		}
+	}
+}
+
+// TestParseDockerConfig tests auth and username/password entries
+func TestParseDockerConfig(t *testing.T) {
+	config := `{"auths":{
+		"https://index.docker.io/v1/":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("hub:secret")) + `"},
+		"ghcr.io":{"username":"bot","password":"ghp_token"}}}`
+
+	keychain, err := ParseDockerConfig([]byte(config))
+	if err != nil {
+		t.Fatalf("ParseDockerConfig() error = %v", err)
+	}
+	if got := keychain[DockerHub]; got != (Credentials{Username: "hub", Password: "secret"}) {
+		t.Errorf("keychain[%s] = %+v", DockerHub, got)
+	}
+	if got := keychain["ghcr.io"]; got != (Credentials{Username: "bot", Password: "ghp_token"}) {
+		t.Errorf("keychain[ghcr.io] = %+v", got)
+	}
+}
+
+// standInRegistry serves one token-protected repository with a tag and,
+// optionally, an image config and a cosign signature made with key
+type standInRegistry struct {
+	manifest  []byte
+	config    []byte
+	signature []byte
+	payload   []byte
+}
+
+func (reg *standInRegistry) digest() string {
+	return blobDigest(reg.manifest)
+}
+
+func blobDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// sign signs the manifest digest for the repository dockerReference
+func (reg *standInRegistry) sign(t *testing.T, key *ecdsa.PrivateKey, dockerReference string) {
+	t.Helper()
+	reg.payload = []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, dockerReference, reg.digest()))
+	sum := sha256.Sum256(reg.payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.signature, _ = json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"layers": []map[string]interface{}{{
+			"mediaType":   "application/vnd.dev.cosign.simplesigning.v1+json",
+			"digest":      "sha256:" + hex.EncodeToString(sum[:]),
+			"size":        len(reg.payload),
+			"annotations": map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		}},
+	})
+}
+
+func (reg *standInRegistry) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "bot" || pass != "secret" || r.URL.Query().Get("scope") != "repository:acme/migrations:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"registry-token"}`))
+	})
+	challenge := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="stand-in"`, r.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+	mux.HandleFunc("/v2/", challenge)
+	mux.HandleFunc("/v2/acme/migrations/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer registry-token" {
+			challenge(w, r)
+			return
+		}
+		sigTag := strings.Replace(reg.digest(), ":", "-", 1) + ".sig"
+		switch path := strings.TrimPrefix(r.URL.Path, "/v2/acme/migrations/"); {
+		case path == "manifests/v2":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			w.Header().Set("Docker-Content-Digest", reg.digest())
+			_, _ = w.Write(reg.manifest)
+		case path == "manifests/"+sigTag && reg.signature != nil:
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(reg.signature)
+		case path == "blobs/"+blobDigest(reg.config) && reg.config != nil:
+			_, _ = w.Write(reg.config)
+		case strings.HasPrefix(path, "blobs/") && reg.payload != nil:
+			_, _ = w.Write(reg.payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// TestResolveAndVerifySignature tests digest resolution and cosign
+// verification against a stand-in registry over plain HTTP
+func TestResolveAndVerifySignature(t *testing.T) {
+	signer, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	reg := &standInRegistry{manifest: []byte(`{"schemaVersion":2,"layers":[]}`)}
+	srv := reg.server(t)
+	defer srv.Close()
+
+	image := strings.TrimPrefix(srv.URL, "http://") + "/acme/migrations:v2"
+	keychain := Keychain{strings.TrimPrefix(srv.URL, "http://"): {Username: "bot", Password: "secret"}}
+	c := NewClient(true)
+	ctx := context.Background()
+
+	digest, err := c.Resolve(ctx, image, keychain)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if digest != reg.digest() {
+		t.Errorf("Resolve() = %s, expected %s", digest, reg.digest())
+	}
+
+	if _, err := c.Resolve(ctx, image, nil); err == nil {
+		t.Error("Resolve() without credentials should fail")
+	}
+
+	err = c.VerifySignature(ctx, image, digest, publicKeyPEM(t, signer), keychain)
+	if !errors.Is(err, ErrVerification) || !strings.Contains(err.Error(), "no cosign signature found") {
+		t.Errorf("VerifySignature() of an unsigned image error = %v", err)
+	}
+
+	reg.sign(t, signer, strings.TrimPrefix(srv.URL, "http://")+"/acme/migrations")
+	if err := c.VerifySignature(ctx, image, digest, publicKeyPEM(t, signer), keychain); err != nil {
+		t.Errorf("VerifySignature() error = %v", err)
+	}
+	if err := c.VerifySignature(ctx, image, digest, publicKeyPEM(t, other), keychain); !errors.Is(err, ErrVerification) {
+		t.Errorf("VerifySignature() with another key error = %v, expected ErrVerification", err)
+	}
+
+	// A signature of the same digest copied from another repository
+	reg.sign(t, signer, "ghcr.io/other/app")
+	if err := c.VerifySignature(ctx, image, digest, publicKeyPEM(t, signer), keychain); !errors.Is(err, ErrVerification) {
+		t.Errorf("VerifySignature() of a signature for another repository error = %v, expected ErrVerification", err)
+	}
+}
+
+// TestPullLabels tests reading the labels of an image config
+func TestPullLabels(t *testing.T) {
+	config := []byte(`{"architecture":"amd64","os":"linux","config":{"Labels":{"org.opencontainers.image.version":"2.4.1"}}}`)
+	reg := &standInRegistry{
+		config:   config,
+		manifest: []byte(fmt.Sprintf(`{"schemaVersion":2,"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":%q,"size":%d},"layers":[]}`, blobDigest(config), len(config))),
+	}
+	srv := reg.server(t)
+	defer srv.Close()
+
+	image := strings.TrimPrefix(srv.URL, "http://") + "/acme/migrations:v2"
+	keychain := Keychain{strings.TrimPrefix(srv.URL, "http://"): {Username: "bot", Password: "secret"}}
+	ctx := context.Background()
+
+	img, err := NewClient(true).Pull(ctx, image, Platform{OS: "linux", Architecture: "amd64"}, keychain)
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	labels, err := img.Labels(ctx)
+	if err != nil {
+		t.Fatalf("Labels() error = %v", err)
+	}
+	if got := labels["org.opencontainers.image.version"]; got != "2.4.1" {
+		t.Errorf("Labels() version = %q, expected 2.4.1", got)
+	}
+
+	reg.config = nil
+	img, err = NewClient(true).Pull(ctx, image, Platform{OS: "linux", Architecture: "amd64"}, keychain)
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if _, err := img.Labels(ctx); err == nil {
+		t.Error("Labels() of a missing config should fail")
+	}
+}
@@ -0,0 +1,156 @@
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// ErrVerification is wrapped by errors for images that are unsigned or whose
+// signature does not match the public key, as opposed to registry failures
+var ErrVerification = errors.New("image verification failed")
+
+// cosignSignatureAnnotation holds the base64 signature of a signature layer
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// maxPayloadSize bounds the signature payloads read into memory
+const maxPayloadSize = 4 << 20
+
+// VerifySignature checks that digest in image's repository carries a cosign
+// signature made with publicKey (PEM, as written by cosign generate-key-pair).
+// The signed payload must name both the digest and image's repository, so a
+// signature copied from another repository is rejected.
+// Keyless (Fulcio/Rekor) signatures are not supported.
+func (c *Client) VerifySignature(ctx context.Context, image, digest string, publicKey []byte, keychain authn.Keychain) error {
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+	ref, err := ParseReference(image)
+	if err != nil {
+		return err
+	}
+	named, err := c.parse(image)
+	if err != nil {
+		return err
+	}
+
+	// cosign stores signatures under the tag sha256-<hex>.sig
+	sigTag := named.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".sig")
+	sigs, err := c.PullArtifact(ctx, sigTag.String(), keychain)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: no cosign signature found for %s", ErrVerification, PinDigest(image, digest))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch signatures of %s: %w", image, err)
+	}
+	manifest, err := sigs.Manifest()
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature manifest: %v", ErrVerification, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, err := readPayload(sigs, layer.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to fetch signature payload of %s: %w", image, err)
+		}
+		if verifyPayload(key, payload, signature) && payloadMatches(payload, ref, digest) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no signature of %s matches the public key", ErrVerification, PinDigest(image, digest))
+}
+
+// readPayload reads a signature layer; the registry client checks its digest
+func readPayload(sigs *Image, digest v1.Hash) ([]byte, error) {
+	rc, err := sigs.OpenBlob(digest)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	payload, err := io.ReadAll(io.LimitReader(rc, maxPayloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > maxPayloadSize {
+		return nil, fmt.Errorf("signature payload %s is too large", digest)
+	}
+	return payload, nil
+}
+
+// parsePublicKey reads a PEM-encoded PKIX public key
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	return key, nil
+}
+
+// verifyPayload checks a base64 signature over payload the way cosign signs:
+// SHA-256 with ECDSA (ASN.1) or RSA PKCS#1 v1.5, plain Ed25519
+func verifyPayload(key crypto.PublicKey, payload []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, sig)
+	}
+	return false
+}
+
+// payloadMatches reports whether a simple-signing payload signs digest in
+// the repository of ref. cosign writes the repository without tag or digest
+// to critical.identity.docker-reference.
+func payloadMatches(payload []byte, ref Reference, digest string) bool {
+	var out struct {
+		Critical struct {
+			Identity struct {
+				DockerReference string `json:"docker-reference"`
+			} `json:"identity"`
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return false
+	}
+	if out.Critical.Image.DockerManifestDigest != digest {
+		return false
+	}
+	signed, err := ParseReference(out.Critical.Identity.DockerReference)
+	if err != nil {
+		return false
+	}
+	return signed.Registry == ref.Registry && signed.Repository == ref.Repository
+}
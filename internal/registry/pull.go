@@ -0,0 +1,160 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// Platform selects one image of a multi-arch index
+type Platform struct {
+	OS           string
+	Architecture string
+	// Variant is optional (v7, v8); empty matches any variant
+	Variant string
+}
+
+// ParsePlatform parses os/arch[/variant], as in docker --platform
+func ParsePlatform(s string) (Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return Platform{}, fmt.Errorf("invalid platform %q, expected os/arch[/variant]", s)
+	}
+	p := Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+func (p Platform) String() string {
+	if p.Variant != "" {
+		return p.OS + "/" + p.Architecture + "/" + p.Variant
+	}
+	return p.OS + "/" + p.Architecture
+}
+
+// Image is a single-platform image, or an OCI artifact, opened for reading
+type Image struct {
+	// Digest is the digest of the platform manifest (not of the index)
+	Digest string
+
+	img v1.Image
+}
+
+// Pull fetches the manifest of image, following a multi-arch index to the
+// manifest for platform
+func (c *Client) Pull(ctx context.Context, image string, platform Platform, keychain authn.Keychain) (*Image, error) {
+	return c.pull(ctx, image, &platform, keychain)
+}
+
+// PullArtifact fetches the manifest of an OCI artifact, such as one pushed
+// with oras. Artifacts have no platform, so an index is not followed.
+func (c *Client) PullArtifact(ctx context.Context, artifact string, keychain authn.Keychain) (*Image, error) {
+	return c.pull(ctx, artifact, nil, keychain)
+}
+
+func (c *Client) pull(ctx context.Context, image string, platform *Platform, keychain authn.Keychain) (*Image, error) {
+	ref, err := c.parse(image)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := c.get(ctx, ref, keychain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest of %s: %w", image, classify(err))
+	}
+
+	if desc.MediaType.IsIndex() {
+		if platform == nil {
+			return nil, fmt.Errorf("%s is an image index, not an artifact", image)
+		}
+		// Pick the platform here so a missing one is reported as ErrNotFound
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index of %s: %w", image, classify(err))
+		}
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index of %s: %w", image, classify(err))
+		}
+		want := v1.Platform{OS: platform.OS, Architecture: platform.Architecture, Variant: platform.Variant}
+		for _, m := range manifest.Manifests {
+			if m.Platform == nil || !m.Platform.Satisfies(want) {
+				continue
+			}
+			img, err := index.Image(m.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch %s manifest of %s: %w", platform, image, classify(err))
+			}
+			return &Image{Digest: m.Digest.String(), img: img}, nil
+		}
+		return nil, fmt.Errorf("image %s has no %s manifest: %w", image, platform, ErrNotFound)
+	}
+
+	img, err := desc.Image()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest of %s: %w", image, classify(err))
+	}
+	return &Image{Digest: desc.Digest.String(), img: img}, nil
+}
+
+// Labels returns the labels of the image config, such as
+// org.opencontainers.image.version. The config is fetched with the context
+// given to Pull.
+func (img *Image) Labels(ctx context.Context) (map[string]string, error) {
+	config, err := img.img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image config: %w", classify(err))
+	}
+	return config.Config.Labels, nil
+}
+
+// Manifest returns the image manifest, whose layers are the files of an
+// OCI artifact
+func (img *Image) Manifest() (*v1.Manifest, error) {
+	manifest, err := img.img.Manifest()
+	if err != nil {
+		return nil, classify(err)
+	}
+	return manifest, nil
+}
+
+// Extract streams the flattened filesystem of the image as a tar, with the
+// layers and their whiteouts applied as a container runtime would. Layer
+// digests are checked as they are read.
+func (img *Image) Extract() io.ReadCloser {
+	return classifyingReader{mutate.Extract(img.img)}
+}
+
+// OpenBlob streams the layer with digest as stored in the registry
+// (compressed, for image layers). Its digest is checked when the stream is
+// read to EOF, so callers must drain it.
+func (img *Image) OpenBlob(digest v1.Hash) (io.ReadCloser, error) {
+	layer, err := img.img.LayerByDigest(digest)
+	if err != nil {
+		return nil, classify(err)
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch layer %s: %w", digest, classify(err))
+	}
+	return classifyingReader{rc}, nil
+}
+
+// classifyingReader classifies registry errors returned while streaming
+type classifyingReader struct {
+	io.ReadCloser
+}
+
+func (r classifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = classify(err)
+	}
+	return n, err
+}
@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DockerHub is the registry of references without a registry host
+const DockerHub = "index.docker.io"
+
+// digestPattern matches the sha256 manifest digests registries return
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// Reference is a parsed image reference
+type Reference struct {
+	// Registry is the registry host, DockerHub if the reference has none
+	Registry string
+	// Repository is the path within the registry (library/postgres)
+	Repository string
+	// Tag is the tag, latest if neither tag nor digest is given
+	Tag string
+	// Digest is the manifest digest (sha256:...) the reference is pinned to
+	Digest string
+}
+
+// ParseReference parses an image reference such as postgres:16,
+// ghcr.io/acme/migrations:v2 or registry.local:5000/migrations@sha256:...
+func ParseReference(image string) (Reference, error) {
+	ref := Reference{}
+	name := image
+	if before, digest, ok := strings.Cut(name, "@"); ok {
+		if !digestPattern.MatchString(digest) {
+			return Reference{}, fmt.Errorf("image %s has an invalid digest", image)
+		}
+		name, ref.Digest = before, digest
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	host, repository, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Registry, ref.Repository = host, repository
+	} else {
+		ref.Registry, ref.Repository = DockerHub, name
+		if !strings.Contains(name, "/") {
+			ref.Repository = "library/" + name
+		}
+	}
+	if ref.Repository == "" || ref.Repository != strings.ToLower(ref.Repository) {
+		return Reference{}, fmt.Errorf("image %s has an invalid repository name", image)
+	}
+	return ref, nil
+}
+
+// PinDigest replaces the tag or digest of image with digest, keeping the
+// image name as written (postgres:16 -> postgres@sha256:...)
+func PinDigest(image, digest string) string {
+	name, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name + "@" + digest
+}
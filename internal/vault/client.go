@@ -0,0 +1,183 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default mount paths used when the DBUpgrade does not set them
+const (
+	DefaultAuthMount     = "kubernetes"
+	DefaultDatabaseMount = "database"
+)
+
+// Client talks to the Vault HTTP API with a shared connection pool.
+// It should be created once at startup and reused across reconciles.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a new Vault client with connection pooling
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 20,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Config identifies a Vault server and the Kubernetes auth login to use
+type Config struct {
+	// Address is the Vault server URL (e.g. https://vault.vault.svc:8200)
+	Address string
+	// AuthMount is the Kubernetes auth mount path (defaults to kubernetes)
+	AuthMount string
+	// AuthRole is the Kubernetes auth role to log in with
+	AuthRole string
+	// JWT is the ServiceAccount token presented to Kubernetes auth.
+	// It should belong to the DBUpgrade's namespace so Vault roles can be
+	// bound per tenant (bound_service_account_namespaces).
+	JWT string
+}
+
+// Lease holds dynamic database credentials issued by the database secrets engine
+type Lease struct {
+	// ID is used to revoke the credentials
+	ID string
+	// Duration is how long the credentials are valid for
+	Duration time.Duration
+	Username string
+	Password string
+}
+
+// GenerateCredentials logs in and requests credentials for role from the
+// database secrets engine mounted at mount
+func (c *Client) GenerateCredentials(ctx context.Context, cfg Config, mount, role string) (*Lease, error) {
+	token, err := c.login(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if mount == "" {
+		mount = DefaultDatabaseMount
+	}
+	var out struct {
+		LeaseID       string `json:"lease_id"`
+		LeaseDuration int    `json:"lease_duration"`
+		Data          struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, cfg.Address, http.MethodGet, fmt.Sprintf("%s/creds/%s", mount, role), token, nil, &out); err != nil {
+		return nil, fmt.Errorf("failed to generate database credentials for role %s: %w", role, err)
+	}
+	if out.Data.Username == "" || out.Data.Password == "" {
+		return nil, fmt.Errorf("vault returned no credentials for role %s", role)
+	}
+
+	return &Lease{
+		ID:       out.LeaseID,
+		Duration: time.Duration(out.LeaseDuration) * time.Second,
+		Username: out.Data.Username,
+		Password: out.Data.Password,
+	}, nil
+}
+
+// RevokeLease logs in and revokes a lease, which drops the database user
+func (c *Client) RevokeLease(ctx context.Context, cfg Config, leaseID string) error {
+	token, err := c.login(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{"lease_id": leaseID}
+	if err := c.do(ctx, cfg.Address, http.MethodPut, "sys/leases/revoke", token, body, nil); err != nil {
+		return fmt.Errorf("failed to revoke lease %s: %w", leaseID, err)
+	}
+	return nil
+}
+
+// login exchanges the ServiceAccount token for a Vault token
+func (c *Client) login(ctx context.Context, cfg Config) (string, error) {
+	mount := cfg.AuthMount
+	if mount == "" {
+		mount = DefaultAuthMount
+	}
+
+	var out struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role": cfg.AuthRole, "jwt": cfg.JWT}
+	if err := c.do(ctx, cfg.Address, http.MethodPost, fmt.Sprintf("auth/%s/login", mount), "", body, &out); err != nil {
+		return "", fmt.Errorf("failed to log in to Vault with role %s: %w", cfg.AuthRole, err)
+	}
+	if out.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login with role %s returned no token", cfg.AuthRole)
+	}
+	return out.Auth.ClientToken, nil
+}
+
+// do sends a request to the Vault API and decodes the response into out
+func (c *Client) do(ctx context.Context, address, method, path, token string, in, out interface{}) error {
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(address, "/"), strings.Trim(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		if len(apiErr.Errors) > 0 {
+			return fmt.Errorf("%s: %s", resp.Status, strings.Join(apiErr.Errors, "; "))
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,85 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStandInVault serves the Kubernetes auth, database creds and lease
+// revocation endpoints used by Client, recording revoked lease IDs
+func newStandInVault(t *testing.T, revoked *[]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if in["role"] != "migrator" || in["jwt"] != "sa-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"s.vault-token"}}`))
+	})
+	mux.HandleFunc("/v1/database/creds/app-migrations", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"lease_id":"database/creds/app-migrations/abc","lease_duration":3600,"data":{"username":"v-migrator-abc","password":"A1a-secret"}}`))
+	})
+	mux.HandleFunc("/v1/sys/leases/revoke", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		*revoked = append(*revoked, in["lease_id"])
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return httptest.NewServer(mux)
+}
+
+// TestGenerateCredentialsAndRevoke tests the lease lifecycle against a stand-in Vault
+func TestGenerateCredentialsAndRevoke(t *testing.T) {
+	var revoked []string
+	srv := newStandInVault(t, &revoked)
+	defer srv.Close()
+
+	c := NewClient()
+	cfg := Config{Address: srv.URL, AuthRole: "migrator", JWT: "sa-token"}
+
+	lease, err := c.GenerateCredentials(context.Background(), cfg, "", "app-migrations")
+	if err != nil {
+		t.Fatalf("GenerateCredentials() error = %v", err)
+	}
+	if lease.Username != "v-migrator-abc" || lease.Password != "A1a-secret" {
+		t.Errorf("unexpected credentials %+v", lease)
+	}
+	if lease.Duration != time.Hour {
+		t.Errorf("lease duration = %s, expected 1h", lease.Duration)
+	}
+
+	if err := c.RevokeLease(context.Background(), cfg, lease.ID); err != nil {
+		t.Fatalf("RevokeLease() error = %v", err)
+	}
+	if len(revoked) != 1 || revoked[0] != lease.ID {
+		t.Errorf("revoked leases = %v, expected [%s]", revoked, lease.ID)
+	}
+}
+
+// TestLoginDenied tests that Vault errors are surfaced
+func TestLoginDenied(t *testing.T) {
+	var revoked []string
+	srv := newStandInVault(t, &revoked)
+	defer srv.Close()
+
+	c := NewClient()
+	cfg := Config{Address: srv.URL, AuthRole: "other-tenant", JWT: "sa-token"}
+
+	_, err := c.GenerateCredentials(context.Background(), cfg, "", "app-migrations")
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("GenerateCredentials() error = %v, expected permission denied", err)
+	}
+}
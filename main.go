@@ -37,7 +37,11 @@ import (
 	dbupgradev1alpha1 "github.com/subganapathy/automatic-db-upgrades/api/v1alpha1"
 	"github.com/subganapathy/automatic-db-upgrades/controllers"
 	awsutil "github.com/subganapathy/automatic-db-upgrades/internal/aws"
+	"github.com/subganapathy/automatic-db-upgrades/internal/checks"
 	appmetrics "github.com/subganapathy/automatic-db-upgrades/internal/metrics"
+	"github.com/subganapathy/automatic-db-upgrades/internal/prometheus"
+	"github.com/subganapathy/automatic-db-upgrades/internal/registry"
+	"github.com/subganapathy/automatic-db-upgrades/internal/vault"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -118,17 +122,24 @@ func main() {
 		setupLog.Info("AWS client manager initialized successfully")
 	}
 
+	registryClient := registry.NewClient(controllers.AllowInsecureRegistries)
 	if err = (&controllers.DBUpgradeReconciler{
-		Client:           mgr.GetClient(),
-		Scheme:           mgr.GetScheme(),
-		RestConfig:       mgr.GetConfig(),
-		AWSClientManager: awsClientManager,
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		RestConfig:           mgr.GetConfig(),
+		AWSClientManager:     awsClientManager,
+		VaultClient:          vault.NewClient(),
+		RegistryClient:       registryClient,
+		PrometheusClient:     prometheus.NewClient(),
+		HTTPClient:           checks.NewHTTPClient(),
+		ImageVersionResolver: checks.NewImageVersionResolver(registryClient),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DBUpgrade")
 		os.Exit(1)
 	}
 	// Enable webhooks unless DISABLE_WEBHOOKS is set (useful for E2E testing without cert-manager)
 	if os.Getenv("DISABLE_WEBHOOKS") != "true" {
+		dbupgradev1alpha1.AllowInsecureVault = controllers.AllowInsecureVault
 		if err = (&dbupgradev1alpha1.DBUpgrade{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "DBUpgrade")
 			os.Exit(1)